@@ -0,0 +1,177 @@
+package json
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Canonical encodes a value using the JSON Canonicalization Scheme of RFC 8785: object keys
+// sorted by UTF-16 code units, no insignificant whitespace, numbers formatted as in
+// ECMAScript and minimal string escaping. Values are first encoded with encoding/json.
+func Canonical(v any) ([]byte, error) {
+	tree, err := normalize(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = canonical(&buf, tree); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CanonicalHash returns the SHA-256 hash of a value's canonical encoding.
+func CanonicalHash(v any) ([]byte, error) {
+	b, err := Canonical(v)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	return sum[:], nil
+}
+
+// CanonicalHMAC returns the HMAC-SHA256 of a value's canonical encoding.
+func CanonicalHMAC(key []byte, v any) ([]byte, error) {
+	b, err := Canonical(v)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return mac.Sum(nil), nil
+}
+
+// canonical writes the canonical encoding of a decoded JSON tree.
+func canonical(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		canonicalString(buf, v)
+	case json.Number:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return fmt.Errorf("number %s cannot be canonicalized: %w", v, err)
+		}
+		buf.WriteString(formatNumber(f))
+	case []any:
+		buf.WriteByte('[')
+		for i, child := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := canonical(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			canonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := canonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected %T in decoded json", v)
+	}
+	return nil
+}
+
+// lessUTF16 compares strings by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	x, y := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
+
+// canonicalString writes a string, escaping only what JSON requires.
+func canonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formatNumber formats a number as ECMAScript's Number.prototype.toString does.
+func formatNumber(f float64) string {
+	if f == 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		// NaN and Inf cannot come from decoded JSON
+		return "0"
+	}
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+	// shortest round-tripping digits and exponent, as d.ddde±x
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	k, n := len(digits), e+1
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+	s := digits[:1]
+	if k > 1 {
+		s += "." + digits[1:]
+	}
+	if n-1 >= 0 {
+		return sign + s + "e+" + strconv.Itoa(n-1)
+	}
+	return sign + s + "e-" + strconv.Itoa(1-n)
+}
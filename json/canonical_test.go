@@ -0,0 +1,71 @@
+package json
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	input := `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false]}`
+	expected := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	out, err := Canonical(decodeDoc(t, input))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(out) != expected {
+		t.Fatalf("expected %s, got %s", expected, out)
+	}
+}
+
+func TestCanonical_SortsByUTF16(t *testing.T) {
+	input := `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh", "1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`
+	expected := "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+	out, err := Canonical(decodeDoc(t, input))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(out) != expected {
+		t.Fatalf("expected %s, got %s", expected, out)
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	cases := map[float64]string{
+		0:                      "0",
+		math.Copysign(0, -1):   "0",
+		1:                      "1",
+		-1.5:                   "-1.5",
+		1e20:                   "100000000000000000000",
+		1e21:                   "1e+21",
+		1.5e300:                "1.5e+300",
+		0.000001:               "0.000001",
+		0.0000001:              "1e-7",
+		123456789012345680000:  "123456789012345680000",
+		9007199254740992:       "9007199254740992",
+		math.MaxFloat64:        "1.7976931348623157e+308",
+		math.Nextafter(0.3, 1): "0.30000000000000004",
+		5e-324:                 "5e-324",
+	}
+	for f, expected := range cases {
+		if s := formatNumber(f); s != expected {
+			t.Errorf("formatNumber(%v): expected %s, got %s", f, expected, s)
+		}
+	}
+}
+
+func TestCanonicalHash(t *testing.T) {
+	a, err := CanonicalHash(map[string]any{"b": 1, "a": []int{1, 2}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	b, _ := CanonicalHash(decodeDoc(t, `{ "a": [1.0, 2], "b": 1e0 }`))
+	if hex.EncodeToString(a) != hex.EncodeToString(b) {
+		t.Fatalf("expected equal hashes for equivalent documents")
+	}
+	m1, _ := CanonicalHMAC([]byte("k1"), testStruct{"a", 1})
+	m2, _ := CanonicalHMAC([]byte("k2"), testStruct{"a", 1})
+	if len(m1) != 32 || hex.EncodeToString(m1) == hex.EncodeToString(m2) {
+		t.Fatalf("expected distinct 32-byte HMACs for different keys")
+	}
+}
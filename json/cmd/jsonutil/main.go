@@ -0,0 +1,312 @@
+// Command jsonutil exposes the json package for shell scripts.
+//
+// Usage:
+//
+//	jsonutil pretty [-comments] [file ...]
+//	jsonutil compact [-comments] [file ...]
+//	jsonutil canonical [-comments] [file ...]
+//	jsonutil validate [-comments] [-schema file] [file ...]
+//	jsonutil get [-comments] pointer [file ...]
+//	jsonutil merge [-comments] [-arrays replace|append|index] file ...
+//	jsonutil diff [-comments] [-color] file1 file2
+//
+// With no files, or a file named "-", jsonutil reads standard input. It exits with status 2
+// on usage errors, 1 on any other error, and diff also exits with status 1 when the files
+// differ.
+package main
+
+import (
+	"bufio"
+	stdjson "encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/doggystylez/utils/json"
+)
+
+// errDiffer reports that diff found differences, which have already been printed.
+var errDiffer = errors.New("files differ")
+
+// usageError reports invalid arguments, which exit with status 2.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// commands maps each subcommand to its implementation.
+var commands = map[string]func(args []string, out io.Writer) error{
+	"pretty":    pretty,
+	"compact":   compact,
+	"canonical": canonical,
+	"validate":  validate,
+	"get":       get,
+	"merge":     merge,
+	"diff":      diff,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	out := bufio.NewWriter(os.Stdout)
+	err := cmd(os.Args[2:], out)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil && !errors.Is(err, errDiffer) {
+		fmt.Fprintln(os.Stderr, "jsonutil:", err)
+	}
+	os.Exit(status(err))
+}
+
+// status returns the exit status for the error returned by a subcommand.
+func status(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &usageErr):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// usage prints the available commands and exits.
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: jsonutil pretty|compact|canonical|validate|get|merge|diff [flags] [args]")
+	os.Exit(2)
+}
+
+// flags returns a flag set for a subcommand with the flags shared by every command.
+func flags(name string) (*flag.FlagSet, *json.LoadOpts) {
+	fs := flag.NewFlagSet("jsonutil "+name, flag.ExitOnError)
+	opts := &json.LoadOpts{UseNumber: true, DisallowTrailingData: true}
+	fs.BoolVar(&opts.AllowComments, "comments", false, "allow // and /* */ comments in the input")
+	return fs, opts
+}
+
+// inputs returns the files to read, defaulting to standard input.
+func inputs(args []string) []string {
+	if len(args) == 0 {
+		return []string{"-"}
+	}
+	return args
+}
+
+// load decodes a file, or standard input when file is "-".
+func load(file string, opts *json.LoadOpts) (any, error) {
+	var doc *any
+	var err error
+	if file == "-" {
+		if doc, err = json.LoadReader[any](os.Stdin, opts); err != nil {
+			return nil, fmt.Errorf("stdin: %w", err)
+		}
+	} else if doc, err = json.LoadFileWithOpts[any](file, opts); err != nil {
+		return nil, err
+	}
+	return *doc, nil
+}
+
+// each loads every input and writes the result of format for it on its own line.
+func each(files []string, opts *json.LoadOpts, out io.Writer, format func(any) ([]byte, error)) error {
+	for _, file := range inputs(files) {
+		doc, err := load(file, opts)
+		if err != nil {
+			return err
+		}
+		b, err := format(doc)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(out, "%s\n", b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pretty indents each input as json.Pretty does.
+func pretty(args []string, out io.Writer) error {
+	fs, opts := flags("pretty")
+	_ = fs.Parse(args)
+	return each(fs.Args(), opts, out, func(doc any) ([]byte, error) {
+		s, err := json.Pretty(doc)
+		return []byte(s), err
+	})
+}
+
+// compact removes insignificant whitespace from each input.
+func compact(args []string, out io.Writer) error {
+	fs, opts := flags("compact")
+	_ = fs.Parse(args)
+	return each(fs.Args(), opts, out, stdjson.Marshal)
+}
+
+// canonical writes the RFC 8785 canonical form of each input.
+func canonical(args []string, out io.Writer) error {
+	fs, opts := flags("canonical")
+	_ = fs.Parse(args)
+	return each(fs.Args(), opts, out, json.Canonical)
+}
+
+// validate checks that every input is well formed and, with -schema, matches the schema.
+// It reports every invalid input before failing.
+func validate(args []string, _ io.Writer) error {
+	fs, opts := flags("validate")
+	schemaFile := fs.String("schema", "", "JSON Schema file the inputs must match")
+	_ = fs.Parse(args)
+	var schema *json.Schema
+	if *schemaFile != "" {
+		var err error
+		if schema, err = json.LoadFile[json.Schema](*schemaFile); err != nil {
+			return err
+		}
+	}
+	var failed bool
+	for _, file := range inputs(fs.Args()) {
+		doc, err := load(file, opts)
+		if err == nil && schema != nil {
+			if err = schema.Validate(doc); err != nil {
+				err = fmt.Errorf("%s:\n%w", file, err)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		}
+	}
+	if failed {
+		return errors.New("validation failed")
+	}
+	return nil
+}
+
+// get writes the value at a JSON Pointer in each input.
+func get(args []string, out io.Writer) error {
+	fs, opts := flags("get")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 {
+		return &usageError{"get: missing pointer"}
+	}
+	p, err := json.ParsePointer(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return each(fs.Args()[1:], opts, out, func(doc any) ([]byte, error) {
+		v, err := p.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		s, err := json.Pretty(v)
+		return []byte(s), err
+	})
+}
+
+// merge deep-merges the files in order, later files taking precedence.
+func merge(args []string, out io.Writer) error {
+	fs, opts := flags("merge")
+	arrays := fs.String("arrays", "replace", "how arrays combine: replace, append or index")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		return &usageError{"merge: no files"}
+	}
+	layerOpts := &json.LayerOpts{Load: opts}
+	switch *arrays {
+	case "replace":
+		layerOpts.Arrays = json.ArrayReplace
+	case "append":
+		layerOpts.Arrays = json.ArrayAppend
+	case "index":
+		layerOpts.Arrays = json.ArrayMergeIndex
+	default:
+		return &usageError{fmt.Sprintf("merge: invalid -arrays %q", *arrays)}
+	}
+	layers := make([]json.Layer, fs.NArg())
+	stdin := false
+	for i, file := range fs.Args() {
+		layers[i] = json.Layer{File: file}
+		if file != "-" {
+			continue
+		}
+		if stdin {
+			return &usageError{"merge: standard input can only be read once"}
+		}
+		stdin = true
+		tmp, err := spool(os.Stdin)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp)
+		layers[i] = json.Layer{Name: "stdin", File: tmp}
+	}
+	doc, _, err := json.LoadLayers[any](layers, layerOpts)
+	if err != nil {
+		return err
+	}
+	s, err := json.Pretty(*doc)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, s)
+	return err
+}
+
+// spool copies r to a temporary file, since layers are read from files, and returns its name.
+func spool(r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp("", "jsonutil-*.json")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// diff prints the differences between two inputs and fails if there are any.
+func diff(args []string, out io.Writer) error {
+	fs, opts := flags("diff")
+	color := fs.Bool("color", false, "colorize the output")
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		return &usageError{"diff: expected two files"}
+	}
+	if fs.Arg(0) == "-" && fs.Arg(1) == "-" {
+		return &usageError{"diff: standard input can only be read once"}
+	}
+	a, err := load(fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	b, err := load(fs.Arg(1), opts)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Diff(a, b)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if err = json.FormatDiff(out, changes, *color); err != nil {
+		return err
+	}
+	return errDiffer
+}
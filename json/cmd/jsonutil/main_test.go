@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	a := write("a.json", `{"b": 2, "a": [1, 2]}`)
+	b := write("b.json", `{"b": 3, "a": [1, 2]}`)
+	bad := write("bad.json", `{"a": 1} {}`)
+	tests := []struct {
+		name   string
+		cmd    func([]string, io.Writer) error
+		args   []string
+		out    string
+		status int
+	}{
+		{"compact", compact, []string{a}, `{"a":[1,2],"b":2}` + "\n", 0},
+		{"canonical", canonical, []string{a, b}, `{"a":[1,2],"b":2}` + "\n" + `{"a":[1,2],"b":3}` + "\n", 0},
+		{"get", get, []string{"/a/1", a}, "2\n", 0},
+		{"get without pointer", get, nil, "", 2},
+		{"trailing data", compact, []string{bad}, "", 1},
+		{"merge", merge, []string{"-arrays", "append", a, b}, "{\n  \"a\": [\n    1,\n    2,\n    1,\n    2\n  ],\n  \"b\": 3\n}\n", 0},
+		{"merge without files", merge, nil, "", 2},
+		{"merge invalid arrays", merge, []string{"-arrays", "zip", a}, "", 2},
+		{"diff equal", diff, []string{a, a}, "", 0},
+		{"diff", diff, []string{a, b}, "~ /b: 2 -> 3\n", 1},
+		{"diff one file", diff, []string{a}, "", 2},
+		{"diff stdin twice", diff, []string{"-", "-"}, "", 2},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := tt.cmd(tt.args, &out)
+		if got := status(err); got != tt.status {
+			t.Errorf("%s: expected status %d, got %d (%v)", tt.name, tt.status, got, err)
+		}
+		if out.String() != tt.out {
+			t.Errorf("%s: expected output %q, got %q", tt.name, tt.out, out.String())
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	if status(nil) != 0 || status(errDiffer) != 1 || status(errors.New("boom")) != 1 {
+		t.Fatal("unexpected status for plain errors")
+	}
+	if status(&usageError{"bad"}) != 2 {
+		t.Fatal("expected usage errors to exit with status 2")
+	}
+}
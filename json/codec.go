@@ -0,0 +1,122 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Codec compresses and decompresses file contents.
+type Codec interface {
+	NewReader(r io.Reader) (io.ReadCloser, error)
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// Codecs for the compression formats in the standard library. Only Gzip is registered by default.
+var (
+	Gzip  Codec = gzipCodec{}
+	Zlib  Codec = zlibCodec{}
+	Flate Codec = flateCodec{}
+)
+
+type (
+	gzipCodec  struct{}
+	zlibCodec  struct{}
+	flateCodec struct{}
+)
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (zlibCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+func (zlibCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+func (flateCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+func (flateCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.DefaultCompression)
+}
+
+// registration is a codec with the file extension and magic bytes that select it.
+type registration struct {
+	ext   string
+	magic []byte
+	codec Codec
+}
+
+var registry struct {
+	mu     sync.RWMutex
+	codecs []registration
+}
+
+func init() {
+	RegisterCodec(".gz", []byte{0x1f, 0x8b}, Gzip)
+}
+
+// RegisterCodec makes LoadFile and SaveFile compress and decompress files with the given
+// extension, such as ".zz". When loading, files starting with magic are also decompressed
+// regardless of their name. Either may be empty. Later registrations take precedence.
+func RegisterCodec(ext string, magic []byte, c Codec) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.codecs = append([]registration{{strings.ToLower(ext), magic, c}}, registry.codecs...)
+}
+
+// codecForName returns the codec registered for the extension of filename, or nil.
+func codecForName(filename string) Codec {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		return nil
+	}
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	for _, r := range registry.codecs {
+		if r.ext == ext {
+			return r.codec
+		}
+	}
+	return nil
+}
+
+// decompress wraps r in the codec matching its magic bytes or the extension of filename.
+// The returned reader is r itself when no codec matches.
+func decompress(r io.Reader, filename string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	registry.mu.RLock()
+	var c Codec
+	for _, reg := range registry.codecs {
+		if len(reg.magic) == 0 {
+			continue
+		}
+		if head, _ := br.Peek(len(reg.magic)); bytes.Equal(head, reg.magic) {
+			c = reg.codec
+			break
+		}
+	}
+	registry.mu.RUnlock()
+	if c == nil {
+		c = codecForName(filename)
+	}
+	if c == nil {
+		return br, nil
+	}
+	return c.NewReader(br)
+}
@@ -0,0 +1,69 @@
+package json
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveFile_Gzip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "snapshot.json.gz")
+	ts := &testStruct{Field1: "test", Field2: 123}
+	if err := SaveFile(filename, ts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, _ := os.ReadFile(filename)
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		t.Fatalf("expected gzip data, got %q", data)
+	}
+	result, err := LoadFile[testStruct](filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *result != *ts {
+		t.Fatalf("expected %v, got %v", ts, result)
+	}
+}
+
+func TestLoadFile_GzipMagic(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`{"field1":"test","field2":123}`))
+	_ = zw.Close()
+	filename := writeTemp(t, "snapshot.json", buf.String())
+	result, err := LoadFile[testStruct](filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field2 != 123 {
+		t.Fatalf("expected 123, got %v", result.Field2)
+	}
+	_, err = LoadFileWithOpts[testStruct](filename, &LoadOpts{MaxSize: 10})
+	var tooLarge *TooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected size limit to apply after decompression, got %v", err)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec(".zz", nil, Zlib)
+	filename := filepath.Join(t.TempDir(), "snapshot.zz")
+	ts := &testStruct{Field1: "test", Field2: 123}
+	if err := SaveFileWithOpts(filename, ts, &SaveOpts{Atomic: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, _ := os.ReadFile(filename)
+	if data[0] != 0x78 {
+		t.Fatalf("expected zlib data, got %q", data)
+	}
+	result, err := LoadFile[testStruct](filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *result != *ts {
+		t.Fatalf("expected %v, got %v", ts, result)
+	}
+}
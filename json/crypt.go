@@ -0,0 +1,271 @@
+package json
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// EnvelopeVersion is the version of the envelope written by SaveEncrypted.
+	EnvelopeVersion = 1
+	// KeySize is the size of an AES-256 key.
+	KeySize = 32
+
+	cipherAESGCM = "aes-256-gcm"
+	kdfNone      = "none"
+	kdfPBKDF2    = "pbkdf2-sha256"
+
+	// DefaultIterations is the PBKDF2 iteration count used when PassphraseKey.Iterations is zero.
+	DefaultIterations = 600000
+	// MaxIterations bounds the PBKDF2 iteration count, which is read from the unauthenticated
+	// header, so a crafted file cannot stall LoadEncrypted.
+	MaxIterations = 10 * DefaultIterations
+	saltSize      = 16
+)
+
+// ErrDecrypt is returned when an encrypted file cannot be authenticated, because the key
+// is wrong or the file was modified.
+var ErrDecrypt = errors.New("decryption failed")
+
+// Header describes how an encrypted file was sealed. It is authenticated along with the data.
+type Header struct {
+	Version    int    `json:"version"`
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+}
+
+// Envelope is the on-disk format of an encrypted json file.
+type Envelope struct {
+	Header
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// KeySource provides the key for an encrypted file. When sealing, the header has an empty
+// KDF and the source fills in the parameters it used; when opening, it derives the key from
+// the parameters already in the header.
+type KeySource interface {
+	Key(h *Header) ([]byte, error)
+}
+
+// RawKey is a 32 byte AES-256 key used as is.
+type RawKey []byte
+
+// Key implements KeySource.
+func (k RawKey) Key(h *Header) ([]byte, error) {
+	if len(k) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d, expected %d", len(k), KeySize)
+	}
+	if h.KDF == "" {
+		h.KDF = kdfNone
+	}
+	if h.KDF != kdfNone {
+		return nil, fmt.Errorf("file requires kdf %q, got a raw key", h.KDF)
+	}
+	return k, nil
+}
+
+// EnvKey reads a base64 encoded 32 byte key from the named environment variable.
+type EnvKey string
+
+// Key implements KeySource.
+func (k EnvKey) Key(h *Header) ([]byte, error) {
+	value, ok := os.LookupEnv(string(k))
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", string(k))
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %s: %w", string(k), err)
+	}
+	return RawKey(key).Key(h)
+}
+
+// PassphraseKey derives the key from a passphrase with PBKDF2-HMAC-SHA256 and a random salt.
+type PassphraseKey struct {
+	Passphrase string
+	// Iterations is the PBKDF2 iteration count used when sealing. Defaults to DefaultIterations.
+	Iterations int
+}
+
+// Key implements KeySource.
+func (k PassphraseKey) Key(h *Header) ([]byte, error) {
+	if h.KDF == "" {
+		h.KDF = kdfPBKDF2
+		h.Iterations = k.Iterations
+		if h.Iterations <= 0 {
+			h.Iterations = DefaultIterations
+		}
+		h.Salt = make([]byte, saltSize)
+		if _, err := io.ReadFull(rand.Reader, h.Salt); err != nil {
+			return nil, err
+		}
+	}
+	if h.KDF != kdfPBKDF2 {
+		return nil, fmt.Errorf("file requires kdf %q, got a passphrase", h.KDF)
+	}
+	if h.Iterations <= 0 || len(h.Salt) == 0 {
+		return nil, errors.New("missing pbkdf2 parameters")
+	}
+	if h.Iterations > MaxIterations {
+		return nil, fmt.Errorf("pbkdf2 iterations %d exceed the maximum of %d", h.Iterations, MaxIterations)
+	}
+	return pbkdf2([]byte(k.Passphrase), h.Salt, h.Iterations, KeySize), nil
+}
+
+// pbkdf2 derives a key of keyLen bytes as specified in RFC 8018 with HMAC-SHA256.
+func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	var counter [4]byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], block)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// Seal encrypts plaintext into a new envelope with a key from key.
+func Seal(plaintext []byte, key KeySource) (*Envelope, error) {
+	e := &Envelope{Header: Header{Version: EnvelopeVersion, Cipher: cipherAESGCM}}
+	aead, aad, err := e.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	e.Nonce = make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, e.Nonce); err != nil {
+		return nil, err
+	}
+	e.Data = aead.Seal(nil, e.Nonce, plaintext, aad)
+	return e, nil
+}
+
+// Open decrypts the envelope with a key from key.
+func (e *Envelope) Open(key KeySource) ([]byte, error) {
+	if e.Version != EnvelopeVersion {
+		return nil, fmt.Errorf("unsupported envelope version %d", e.Version)
+	}
+	if e.Cipher != cipherAESGCM {
+		return nil, fmt.Errorf("unsupported cipher %q", e.Cipher)
+	}
+	if e.KDF == "" {
+		return nil, errors.New("missing kdf")
+	}
+	aead, aad, err := e.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, e.Nonce, e.Data, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// aead returns the cipher for the envelope and the header bytes it authenticates.
+func (e *Envelope) aead(key KeySource) (cipher.AEAD, []byte, error) {
+	k, err := key.Key(&e.Header)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	aad, err := json.Marshal(e.Header)
+	return aead, aad, err
+}
+
+// SaveEncrypted encrypts a struct with AES-256-GCM and saves it atomically as an envelope
+// readable only by the owner, replacing the mode of an existing file.
+func SaveEncrypted(filename string, s any, key KeySource) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return saveSealed(filename, data, key)
+}
+
+// LoadEncrypted loads a file saved by SaveEncrypted into a new struct.
+func LoadEncrypted[T any](filename string, key KeySource) (*T, error) {
+	data, err := openFile(filename, key)
+	if err != nil {
+		return nil, err
+	}
+	var s T
+	if err = decodeValue(data, &s, nil); err != nil {
+		// the decrypted data is secret, so the error is not located in it
+		return nil, &FileError{File: filename, Err: err}
+	}
+	return &s, nil
+}
+
+// Rotate re-encrypts a file saved by SaveEncrypted under a new key, replacing it atomically.
+func Rotate(filename string, oldKey, newKey KeySource) error {
+	filename = filepath.Clean(filename)
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := openFile(filename, oldKey)
+	if err != nil {
+		return err
+	}
+	return saveSealed(filename, data, newKey)
+}
+
+// openFile reads and decrypts an envelope file.
+func openFile(filename string, key KeySource) ([]byte, error) {
+	var e Envelope
+	if err := MergeFile(filename, &e); err != nil {
+		return nil, err
+	}
+	data, err := e.Open(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return data, nil
+}
+
+// saveSealed encrypts data and saves the envelope atomically.
+func saveSealed(filename string, data []byte, key KeySource) error {
+	e, err := Seal(data, key)
+	if err != nil {
+		return err
+	}
+	return SaveFileWithOpts(filename, e, &SaveOpts{Atomic: true, Perm: 0o600, ForcePerm: true})
+}
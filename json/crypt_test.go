@@ -0,0 +1,154 @@
+package json
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 7914 section 11 test vectors
+	tests := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tt := range tests {
+		got := hex.EncodeToString(pbkdf2([]byte(tt.password), []byte(tt.salt), tt.iterations, 64))
+		if got != tt.want {
+			t.Errorf("pbkdf2(%q, %q, %d) = %s, expected %s", tt.password, tt.salt, tt.iterations, got, tt.want)
+		}
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	key := RawKey(bytes.Repeat([]byte{1}, KeySize))
+	filename := filepath.Join(t.TempDir(), "secret.json")
+	in := testStruct{Field1: "token", Field2: 7}
+	if err := SaveEncrypted(filename, in, key); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "token") || !strings.Contains(string(data), `"kdf":"none"`) {
+		t.Fatalf("unexpected envelope %s", data)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	out, err := LoadEncrypted[testStruct](filename, key)
+	if err != nil || *out != in {
+		t.Fatalf("expected %+v, got %+v, %v", in, out, err)
+	}
+	wrong := RawKey(bytes.Repeat([]byte{2}, KeySize))
+	if _, err = LoadEncrypted[testStruct](filename, wrong); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+	if _, err = LoadEncrypted[testStruct](filename, PassphraseKey{Passphrase: "x"}); err == nil {
+		t.Fatal("expected kdf mismatch error")
+	}
+}
+
+func TestEncryptedErrorHidesPlaintext(t *testing.T) {
+	key := RawKey(bytes.Repeat([]byte{1}, KeySize))
+	filename := filepath.Join(t.TempDir(), "secret.json")
+	if err := SaveEncrypted(filename, map[string]string{"field1": "hunter2", "field2": "hunter3"}, key); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err := LoadEncrypted[testStruct](filename, key)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.File != filename {
+		t.Fatalf("expected error naming the file, got %v", err)
+	}
+	if strings.Contains(err.Error(), "hunter") {
+		t.Fatalf("expected plaintext to be hidden, got %v", err)
+	}
+}
+
+func TestEncryptedTampered(t *testing.T) {
+	key := RawKey(bytes.Repeat([]byte{1}, KeySize))
+	e, err := Seal([]byte(`{"field1":"a"}`), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Version = 2
+	if _, err = e.Open(key); err == nil {
+		t.Fatal("expected unsupported version error")
+	}
+	e.Version = EnvelopeVersion
+	e.Data[0] ^= 1
+	if _, err = e.Open(key); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestEncryptedRotate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secret.json")
+	pass := PassphraseKey{Passphrase: "correct horse", Iterations: 1000}
+	in := testStruct{Field1: "token", Field2: 7}
+	if err := SaveEncrypted(filename, in, pass); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	out, err := LoadEncrypted[testStruct](filename, PassphraseKey{Passphrase: "correct horse"})
+	if err != nil || *out != in {
+		t.Fatalf("expected %+v, got %+v, %v", in, out, err)
+	}
+	if _, err = LoadEncrypted[testStruct](filename, PassphraseKey{Passphrase: "wrong"}); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+	t.Setenv("TEST_JSON_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, KeySize)))
+	if err = Rotate(filename, pass, EnvKey("TEST_JSON_KEY")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err = LoadEncrypted[testStruct](filename, pass); err == nil {
+		t.Fatal("expected old key to be rejected after rotation")
+	}
+	out, err = LoadEncrypted[testStruct](filename, EnvKey("TEST_JSON_KEY"))
+	if err != nil || *out != in {
+		t.Fatalf("expected %+v, got %+v, %v", in, out, err)
+	}
+	if _, err = LoadEncrypted[testStruct](filename, EnvKey("TEST_JSON_MISSING")); err == nil {
+		t.Fatal("expected missing environment variable error")
+	}
+}
+
+func TestEncryptedExistingMode(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(filename, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveEncrypted(filename, testStruct{Field1: "token"}, RawKey(bytes.Repeat([]byte{1}, KeySize))); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestEncryptedIterationLimit(t *testing.T) {
+	e := &Envelope{Header: Header{Version: EnvelopeVersion, Cipher: cipherAESGCM, KDF: kdfPBKDF2, Salt: []byte("salt"), Iterations: 2147483647}}
+	done := make(chan error, 1)
+	go func() {
+		_, err := e.Open(PassphraseKey{Passphrase: "x"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "exceed the maximum") {
+			t.Fatalf("expected iteration limit error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected excessive iterations to be rejected without deriving a key")
+	}
+}
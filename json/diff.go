@@ -0,0 +1,203 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ChangeType is the kind of difference between two documents.
+type ChangeType int
+
+// Change types
+const (
+	Added ChangeType = iota
+	Removed
+	Changed
+)
+
+// ANSI color codes for change types
+var changeColors = []string{
+	Added:   "\033[32m",
+	Removed: "\033[31m",
+	Changed: "\033[33m",
+}
+
+// Symbols for change types
+var changeSymbols = []string{
+	Added:   "+",
+	Removed: "-",
+	Changed: "~",
+}
+
+// String returns the name of the change type.
+func (c ChangeType) String() string {
+	switch c {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	default:
+		return "ChangeType(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
+// Change is a single difference between two documents.
+type Change struct {
+	Type ChangeType
+	// Path is the JSON Pointer of the value that differs.
+	Path string
+	Old  any
+	New  any
+}
+
+// Diff compares two values as JSON documents and returns their differences ordered by path.
+// Values that are not decoded JSON, such as structs, are converted by encoding them first.
+func Diff(a, b any) ([]Change, error) {
+	x, err := normalize(a)
+	if err != nil {
+		return nil, err
+	}
+	y, err := normalize(b)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	diff(x, y, "", &changes)
+	return changes, nil
+}
+
+// DiffFiles compares two json files.
+func DiffFiles(a, b string) ([]Change, error) {
+	x, err := LoadFileWithOpts[any](a, &LoadOpts{UseNumber: true})
+	if err != nil {
+		return nil, err
+	}
+	y, err := LoadFileWithOpts[any](b, &LoadOpts{UseNumber: true})
+	if err != nil {
+		return nil, err
+	}
+	return Diff(*x, *y)
+}
+
+// normalize converts a value to a decoded JSON tree.
+func normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err = decodeValue(b, &out, &LoadOpts{UseNumber: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// diff appends the differences between a and b at path.
+func diff(a, b any, path string, changes *[]Change) {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(x)+len(y))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		for k := range y {
+			if _, ok := x[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + escapeToken(k)
+			xv, inX := x[k]
+			yv, inY := y[k]
+			switch {
+			case !inY:
+				*changes = append(*changes, Change{Removed, p, xv, nil})
+			case !inX:
+				*changes = append(*changes, Change{Added, p, nil, yv})
+			default:
+				diff(xv, yv, p, changes)
+			}
+		}
+		return
+	case []any:
+		y, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < min(len(x), len(y)); i++ {
+			diff(x[i], y[i], path+"/"+strconv.Itoa(i), changes)
+		}
+		for i := len(x); i < len(y); i++ {
+			*changes = append(*changes, Change{Added, path + "/" + strconv.Itoa(i), nil, y[i]})
+		}
+		// removals are listed from the end so the diff can be applied in order as a patch
+		for i := len(x) - 1; i >= len(y); i-- {
+			*changes = append(*changes, Change{Removed, path + "/" + strconv.Itoa(i), x[i], nil})
+		}
+		return
+	}
+	if !Equal(a, b) {
+		*changes = append(*changes, Change{Changed, path, a, b})
+	}
+}
+
+// DiffPatch converts the result of Diff to a JSON Patch that turns the first document into the second.
+func DiffPatch(changes []Change) Patch {
+	patch := make(Patch, len(changes))
+	for i, c := range changes {
+		switch c.Type {
+		case Added:
+			patch[i] = Operation{Op: "add", Path: c.Path, Value: c.New}
+		case Removed:
+			patch[i] = Operation{Op: "remove", Path: c.Path}
+		default:
+			patch[i] = Operation{Op: "replace", Path: c.Path, Value: c.New}
+		}
+	}
+	return patch
+}
+
+// FormatDiff writes a human-readable rendering of changes to w, optionally in color.
+func FormatDiff(w io.Writer, changes []Change, color bool) error {
+	for _, c := range changes {
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		var line string
+		switch c.Type {
+		case Added:
+			line = fmt.Sprintf("%s %s: %s", changeSymbols[c.Type], path, render(c.New))
+		case Removed:
+			line = fmt.Sprintf("%s %s: %s", changeSymbols[c.Type], path, render(c.Old))
+		default:
+			line = fmt.Sprintf("%s %s: %s -> %s", changeSymbols[c.Type], path, render(c.Old), render(c.New))
+		}
+		if color {
+			line = changeColors[c.Type] + line + "\033[0m"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render formats a value compactly for display.
+func render(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
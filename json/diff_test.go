@@ -0,0 +1,89 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := decodeDoc(t, `{"name":"a","port":80,"tags":["x","y","z"],"db":{"host":"h","user":"u"},"gone":true}`)
+	b := decodeDoc(t, `{"name":"a","port":8080,"tags":["x"],"db":{"host":"h2","user":"u","pool":5},"new":null}`)
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var buf bytes.Buffer
+	if err = FormatDiff(&buf, changes, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := `~ /db/host: "h" -> "h2"
++ /db/pool: 5
+- /gone: true
++ /new: null
+~ /port: 80 -> 8080
+- /tags/2: "z"
+- /tags/1: "y"
+`
+	if buf.String() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+	result, err := ApplyPatch(a, DiffPatch(changes))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !Equal(result, b) {
+		t.Fatalf("expected patch to produce %v, got %v", b, result)
+	}
+}
+
+func TestDiff_LargeIntegers(t *testing.T) {
+	decode := func(s string) any {
+		var v any
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return v
+	}
+	a, b := decode(`{"id":9007199254740993}`), decode(`{"id":9007199254740992}`)
+	if Equal(a, b) {
+		t.Fatal("expected large integers to differ")
+	}
+	if !Equal(decode(`1e2`), decode(`100.0`)) || !Equal(decode(`100`), 100.0) {
+		t.Fatal("expected equal numbers in other notations")
+	}
+	changes, err := Diff(a, b)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("expected one change, got %v, %v", changes, err)
+	}
+}
+
+func TestDiff_Structs(t *testing.T) {
+	changes, err := Diff(testStruct{"a", 1}, testStruct{"a", 1})
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v, %v", changes, err)
+	}
+	changes, err = Diff(testStruct{"a", 1}, map[string]any{"field1": "b", "field2": 1.0})
+	expected := []Change{{Changed, "/field1", "a", "b"}}
+	if err != nil || !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %v, got %v, %v", expected, changes, err)
+	}
+}
+
+func TestDiffFiles(t *testing.T) {
+	a := writeTemp(t, "a.json", `{"field1":"test","field2":1}`)
+	b := writeTemp(t, "b.json", `{"field1":"test","field2":2}`)
+	changes, err := DiffFiles(a, b)
+	if err != nil || len(changes) != 1 || changes[0].Path != "/field2" {
+		t.Fatalf("expected change at /field2, got %v, %v", changes, err)
+	}
+	var buf bytes.Buffer
+	_ = FormatDiff(&buf, changes, true)
+	if buf.String() != "\033[33m~ /field2: 1 -> 2\033[0m\n" {
+		t.Fatalf("unexpected colored output %q", buf.String())
+	}
+}
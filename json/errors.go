@@ -0,0 +1,224 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetWidth is the maximum number of bytes of context shown on each side of an error.
+const snippetWidth = 40
+
+// FileError reports where in a json file decoding failed.
+type FileError struct {
+	File string
+	Line int
+	// Column is zero when values were expanded by a Resolver.
+	Column int
+	// Path is the JSON path of the value that failed to decode, such as servers[2].port.
+	// It is only set for type mismatches.
+	Path string
+	// Snippet is the offending line with a caret under the column.
+	Snippet string
+	Err     error
+	// pointer is Path as a JSON Pointer, which stays exact for keys containing . or [.
+	pointer string
+}
+
+func (e *FileError) Error() string {
+	var sb strings.Builder
+	if e.File != "" {
+		sb.WriteString(e.File)
+		sb.WriteString(":")
+	}
+	if e.Line > 0 {
+		sb.WriteString(strconv.Itoa(e.Line))
+		sb.WriteString(":")
+	}
+	if e.Column > 0 {
+		sb.WriteString(strconv.Itoa(e.Column))
+		sb.WriteString(":")
+	}
+	if sb.Len() > 0 {
+		sb.WriteString(" ")
+	}
+	if e.Path != "" {
+		sb.WriteString(e.Path)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Err.Error())
+	if e.Snippet != "" {
+		sb.WriteString("\n")
+		sb.WriteString(e.Snippet)
+	}
+	return sb.String()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// locate wraps a decoding error with the file name and, when known, its position in data.
+func locate(name string, data []byte, err error) error {
+	pos := -1
+	var stack []frame
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var trailingErr *TrailingDataError
+	switch {
+	case errors.As(err, &syntaxErr):
+		pos = int(syntaxErr.Offset) - 1
+	case errors.As(err, &typeErr):
+		stack, pos = pathAt(data, typeErr.Offset)
+	case errors.As(err, &trailingErr):
+		pos = int(trailingErr.Offset) + len(data[trailingErr.Offset:]) - len(bytes.TrimLeft(data[trailingErr.Offset:], " \t\r\n"))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		pos = len(data)
+	}
+	if pos < 0 {
+		if name == "" {
+			return err
+		}
+		return &FileError{File: name, Err: err}
+	}
+	pos = min(pos, len(data))
+	e := &FileError{File: name, Path: formatPath(stack), Err: err, pointer: formatPointer(stack)}
+	e.Line, e.Column, e.Snippet = position(data, pos)
+	return e
+}
+
+// position returns the 1-based line and column of pos and a snippet pointing at it.
+func position(data []byte, pos int) (int, int, string) {
+	start := bytes.LastIndexByte(data[:pos], '\n') + 1
+	end := bytes.IndexByte(data[pos:], '\n')
+	if end < 0 {
+		end = len(data)
+	} else {
+		end += pos
+	}
+	line := bytes.Count(data[:start], []byte{'\n'}) + 1
+	column := utf8.RuneCount(data[start:pos]) + 1
+
+	from, to := start, end
+	prefix, suffix := "", ""
+	if pos-from > snippetWidth {
+		from = pos - snippetWidth
+		for from < pos && !utf8.RuneStart(data[from]) {
+			from++
+		}
+		prefix = "..."
+	}
+	if to-pos > snippetWidth {
+		to = pos + snippetWidth
+		for to > pos && !utf8.RuneStart(data[to]) {
+			to--
+		}
+		suffix = "..."
+	}
+	var sb strings.Builder
+	sb.WriteString("\t")
+	sb.WriteString(prefix)
+	sb.Write(bytes.TrimRight(data[from:to], "\r"))
+	sb.WriteString(suffix)
+	sb.WriteString("\n\t")
+	sb.WriteString(strings.Repeat(" ", len(prefix)))
+	for _, r := range string(data[from:pos]) {
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	sb.WriteString("^")
+	return line, column, sb.String()
+}
+
+// frame is an object or array being walked by pathAt.
+type frame struct {
+	array bool
+	index int
+	key   string
+	inKey bool
+}
+
+// pathAt returns the containers enclosing the innermost value that ends at or after
+// offset and the start position of that value.
+func pathAt(data []byte, offset int64) ([]frame, int) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []frame
+	for {
+		before := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return stack, int(offset)
+		}
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			advance(stack)
+			continue
+		}
+		if n := len(stack); n > 0 && !stack[n-1].array && !stack[n-1].inKey {
+			stack[n-1].key = tok.(string)
+			stack[n-1].inKey = true
+			continue
+		}
+		if dec.InputOffset() >= offset {
+			start := int(before) + len(data[before:]) - len(bytes.TrimLeft(data[before:], " \t\r\n,:"))
+			return stack, start
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, frame{})
+		case json.Delim('['):
+			stack = append(stack, frame{array: true})
+		default:
+			advance(stack)
+		}
+	}
+}
+
+// advance moves the innermost container past the value just read.
+func advance(stack []frame) {
+	if n := len(stack); n > 0 {
+		if stack[n-1].array {
+			stack[n-1].index++
+		} else {
+			stack[n-1].inKey = false
+		}
+	}
+}
+
+// formatPath renders a stack of containers as a path such as servers[2].port.
+func formatPath(stack []frame) string {
+	var sb strings.Builder
+	for _, f := range stack {
+		if f.array {
+			fmt.Fprintf(&sb, "[%d]", f.index)
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteString(f.key)
+	}
+	return sb.String()
+}
+
+// formatPointer renders a stack of containers as a JSON Pointer such as /servers/2/port.
+func formatPointer(stack []frame) string {
+	var sb strings.Builder
+	for _, f := range stack {
+		sb.WriteString("/")
+		if f.array {
+			sb.WriteString(strconv.Itoa(f.index))
+		} else {
+			sb.WriteString(escapeToken(f.key))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testServer struct {
+	Port int `json:"port"`
+}
+
+type testConfig struct {
+	Name    string       `json:"name"`
+	Servers []testServer `json:"servers"`
+}
+
+func TestLoadFile_SyntaxErrorLocation(t *testing.T) {
+	filename := writeTemp(t, "config.json", "{\n\t\"name\": \"a\",\n\t\"servers\": [}\n}")
+	_, err := LoadFile[testConfig](filename)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("expected *FileError, got %v", err)
+	}
+	if fileErr.File != filename || fileErr.Line != 3 || fileErr.Column != 14 {
+		t.Fatalf("expected %s:3:14, got %s:%d:%d", filename, fileErr.File, fileErr.Line, fileErr.Column)
+	}
+	if fileErr.Snippet != "\t\t\"servers\": [}\n\t\t            ^" {
+		t.Fatalf("unexpected snippet %q", fileErr.Snippet)
+	}
+}
+
+func TestLoadFile_TypeErrorPath(t *testing.T) {
+	filename := writeTemp(t, "config.json", `{"servers": [{"port": 1}, {"port": 2}, {"port": "80"}]}`)
+	_, err := LoadFile[testConfig](filename)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("expected *FileError, got %v", err)
+	}
+	if fileErr.Path != "servers[2].port" {
+		t.Fatalf("expected path servers[2].port, got %q", fileErr.Path)
+	}
+	if fileErr.Line != 1 || fileErr.Column != 49 {
+		t.Fatalf("expected 1:49, got %d:%d", fileErr.Line, fileErr.Column)
+	}
+	if !strings.HasPrefix(err.Error(), filename+":1:49: servers[2].port: ") {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
+
+func TestLoadFile_LongLineSnippet(t *testing.T) {
+	filename := writeTemp(t, "config.json", `{"name": "`+strings.Repeat("a", 100)+`", "servers": 1, "padding": "`+strings.Repeat("b", 100)+`"}`)
+	_, err := LoadFile[testConfig](filename)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("expected *FileError, got %v", err)
+	}
+	lines := strings.Split(fileErr.Snippet, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "\t...") || !strings.HasSuffix(lines[0], "...") {
+		t.Fatalf("expected truncated snippet, got %q", fileErr.Snippet)
+	}
+	if caret := strings.Index(lines[1], "^"); lines[0][caret] != '1' {
+		t.Fatalf("expected caret under the offending value, got %q", fileErr.Snippet)
+	}
+}
@@ -0,0 +1,149 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Resolver resolves the reference inside ${...} in a string value.
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to a Resolver.
+type ResolverFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// UnresolvedError is returned by a Resolver for a reference that has no value.
+type UnresolvedError struct {
+	Ref string
+}
+
+func (e *UnresolvedError) Error() string {
+	return fmt.Sprintf("unresolved reference ${%s}", e.Ref)
+}
+
+// EnvResolver resolves ${NAME} and ${NAME:-default} from the environment and
+// ${file:/path} from the trimmed contents of a file, such as a mounted secret.
+var EnvResolver Resolver = ResolverFunc(resolveEnv)
+
+// resolveEnv implements EnvResolver.
+func resolveEnv(ref string) (string, error) {
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if v, ok := os.LookupEnv(name); ok && (v != "" || !hasDefault) {
+		return v, nil
+	}
+	if hasDefault {
+		return def, nil
+	}
+	return "", &UnresolvedError{ref}
+}
+
+// Expand replaces ${...} references inside the string values of a JSON document using r.
+// Object keys are left untouched and $$ escapes a literal $. All failures are returned
+// together, each naming the reference and its line.
+func Expand(data []byte, r Resolver) ([]byte, error) {
+	var out bytes.Buffer
+	var errs []error
+	i := 0
+	for i < len(data) {
+		if data[i] != '"' {
+			out.WriteByte(data[i])
+			i++
+			continue
+		}
+		end := stringEnd(data, i)
+		literal := data[i:end]
+		rest := bytes.TrimLeft(data[end:], " \t\r\n")
+		if bytes.IndexByte(literal, '$') < 0 || (len(rest) > 0 && rest[0] == ':') {
+			out.Write(literal)
+			i = end
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(literal, &s); err != nil {
+			// leave invalid strings for the decoder to report
+			out.Write(literal)
+			i = end
+			continue
+		}
+		line := bytes.Count(data[:i], []byte{'\n'}) + 1
+		expanded, err := expandString(s, r, line)
+		errs = append(errs, err...)
+		b, _ := json.Marshal(expanded)
+		out.Write(b)
+		i = end
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return out.Bytes(), nil
+}
+
+// expandString replaces the references in a single string value.
+func expandString(s string, r Resolver, line int) (string, []error) {
+	var sb strings.Builder
+	var errs []error
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			sb.WriteString(s)
+			return sb.String(), errs
+		}
+		sb.WriteString(s[:i])
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			s = s[i+2:]
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				errs = append(errs, fmt.Errorf("line %d: unterminated reference %q", line, s[i:]))
+				return sb.String(), errs
+			}
+			ref := s[i+2 : i+end]
+			v, err := r.Resolve(ref)
+			var unresolved *UnresolvedError
+			switch {
+			case errors.As(err, &unresolved):
+				errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			case err != nil:
+				errs = append(errs, fmt.Errorf("line %d: ${%s}: %w", line, ref, err))
+			}
+			sb.WriteString(v)
+			s = s[i+end+1:]
+		default:
+			sb.WriteByte('$')
+			s = s[i+1:]
+		}
+	}
+}
+
+// stringEnd returns the index after the string literal starting at data[start].
+func stringEnd(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
@@ -0,0 +1,73 @@
+package json
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	secret := writeTemp(t, "db", "hunter2\n")
+	t.Setenv("TEST_EXPAND_HOST", "db.local")
+	t.Setenv("TEST_EXPAND_EMPTY", "")
+	input := `{"${TEST_EXPAND_HOST}": "${TEST_EXPAND_HOST}:${TEST_EXPAND_PORT:-5432}", "pass": "${file:` + secret + `}", "empty": "${TEST_EXPAND_EMPTY:-x}${TEST_EXPAND_EMPTY}", "cost": "$$5 $"}`
+	expected := `{"${TEST_EXPAND_HOST}": "db.local:5432", "pass": "hunter2", "empty": "x", "cost": "$5 $"}`
+	out, err := Expand([]byte(input), EnvResolver)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(out) != expected {
+		t.Fatalf("expected %s, got %s", expected, out)
+	}
+}
+
+func TestExpand_ReportsAllUnresolved(t *testing.T) {
+	input := "{\n\"a\": \"${TEST_EXPAND_MISSING_A}\",\n\"b\": [\"${TEST_EXPAND_MISSING_B}\"],\n\"c\": \"${file:" + filepath.Join(t.TempDir(), "missing") + "}\"\n}"
+	_, err := Expand([]byte(input), EnvResolver)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	var unresolved *UnresolvedError
+	if !errors.As(err, &unresolved) || unresolved.Ref != "TEST_EXPAND_MISSING_A" {
+		t.Fatalf("expected UnresolvedError for the first reference, got %v", err)
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "line 2: ") || !strings.HasPrefix(lines[1], "line 3: ") || !strings.HasPrefix(lines[2], "line 4: ") {
+		t.Fatalf("expected one error per reference, got %v", err)
+	}
+}
+
+func TestLoadFileWithOpts_Resolver(t *testing.T) {
+	filename := writeTemp(t, "test.json", `{"field1": "${TEST_EXPAND_NAME}", "field2": 1}`)
+	resolver := ResolverFunc(func(ref string) (string, error) {
+		return strings.ToUpper(ref), nil
+	})
+	result, err := LoadFileWithOpts[testStruct](filename, &LoadOpts{Resolver: resolver})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field1 != "TEST_EXPAND_NAME" {
+		t.Fatalf("expected resolved value, got %v", result.Field1)
+	}
+}
+
+func TestLoadFileWithOpts_ResolverErrorHidesValues(t *testing.T) {
+	resolver := ResolverFunc(func(ref string) (string, error) {
+		return "hunter2-" + ref, nil
+	})
+	for _, content := range []string{
+		"{\"field1\": \"${A}\",\n\"field2\": \"${B}\"}",
+		"{\"field1\": \"${A}\"\n\"field2\": 1}",
+	} {
+		filename := writeTemp(t, "test.json", content)
+		_, err := LoadFileWithOpts[testStruct](filename, &LoadOpts{Resolver: resolver})
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) || fileErr.Line != 2 {
+			t.Fatalf("expected error on line 2, got %v", err)
+		}
+		if strings.Contains(err.Error(), "hunter2") {
+			t.Fatalf("expected resolved values to be hidden, got %v", err)
+		}
+	}
+}
@@ -2,47 +2,42 @@ package json
 
 import (
 	"encoding/json"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
 
 // LoadFile loads a json file into a new struct
 func LoadFile[T any](filename string) (*T, error) {
-	file, err := os.Open(filepath.Clean(filename))
-	if err != nil {
-		return nil, err
-	}
-	var s T
-	err = json.NewDecoder(file).Decode(&s)
-	if err != nil {
-		return nil, err
-	}
-	_ = file.Close()
-	return &s, nil
+	return LoadFileWithOpts[T](filename, nil)
 }
 
 // MergeFile takes a json file and loads it into an existing struct
 func MergeFile(filename string, s any) error {
-	file, err := os.Open(filepath.Clean(filename))
-	if err != nil {
-		return err
-	}
-	err = json.NewDecoder(file).Decode(s)
-	if err != nil {
-		return err
-	}
-	_ = file.Close()
-	return nil
+	return MergeFileWithOpts(filename, s, nil)
 }
 
-// SaveFile saves a struct to a json file
+// SaveFile saves a struct to a json file, compressing it if its extension has a registered codec
 func SaveFile(filename string, s any) error {
-	file, err := os.Create(filepath.Clean(filename))
+	return saveFile(filename, s, 0o666, false)
+}
+
+// saveFile truncates or creates filename with perm, applying perm to an existing file if
+// force is set, and writes s to it
+func saveFile(filename string, s any, perm fs.FileMode, force bool) error {
+	file, err := os.OpenFile(filepath.Clean(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
 		return err
 	}
-	err = json.NewEncoder(file).Encode(s)
-	_ = file.Close()
+	if force {
+		err = file.Chmod(perm)
+	}
+	if err == nil {
+		err = encode(file, filename, s)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	return err
 }
 
@@ -0,0 +1,139 @@
+package json
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrUnterminatedComment is returned when a block comment is not closed.
+var ErrUnterminatedComment = errors.New("unterminated block comment")
+
+// commentReader strips comments and trailing commas from a JSON stream.
+type commentReader struct {
+	r        *bufio.Reader
+	out      []byte
+	pending  []byte
+	comma    bool
+	inString bool
+	escape   bool
+	err      error
+}
+
+// NewCommentReader returns a reader that strips // and /* */ comments and trailing commas
+// from r. Removed characters are replaced with spaces and newlines are kept, so line and
+// column numbers in decoding errors match the original input.
+func NewCommentReader(r io.Reader) io.Reader {
+	return &commentReader{r: bufio.NewReader(r)}
+}
+
+// Read implements io.Reader.
+func (c *commentReader) Read(p []byte) (int, error) {
+	for len(c.out) == 0 && c.err == nil {
+		c.step()
+	}
+	n := copy(p, c.out)
+	c.out = c.out[n:]
+	if len(c.out) == 0 && c.err != nil {
+		return n, c.err
+	}
+	return n, nil
+}
+
+// step processes the next byte of input.
+func (c *commentReader) step() {
+	b, err := c.r.ReadByte()
+	if err != nil {
+		c.flush()
+		c.err = err
+		return
+	}
+	if c.inString {
+		c.emit(b)
+		switch {
+		case c.escape:
+			c.escape = false
+		case b == '\\':
+			c.escape = true
+		case b == '"':
+			c.inString = false
+		}
+		return
+	}
+	switch b {
+	case ' ', '\t', '\r', '\n':
+		c.emit(b)
+	case '/':
+		c.comment()
+	case ',':
+		c.flush()
+		c.comma = true
+		c.pending = append(c.pending, b)
+	case '}', ']':
+		if c.comma {
+			c.pending[0] = ' '
+		}
+		c.flush()
+		c.emit(b)
+	default:
+		c.flush()
+		c.emit(b)
+		c.inString = b == '"'
+	}
+}
+
+// comment blanks out a comment that starts with the slash just read.
+func (c *commentReader) comment() {
+	next, err := c.r.Peek(1)
+	if err != nil || (next[0] != '/' && next[0] != '*') {
+		c.flush()
+		c.emit('/')
+		return
+	}
+	block := next[0] == '*'
+	_, _ = c.r.ReadByte()
+	c.emit(' ')
+	c.emit(' ')
+	star := false
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			if block && err == io.EOF {
+				err = ErrUnterminatedComment
+			}
+			c.flush()
+			c.err = err
+			return
+		}
+		switch {
+		case b == '\n':
+			c.emit(b)
+			if !block {
+				return
+			}
+		case block && star && b == '/':
+			c.emit(' ')
+			return
+		case utf8.RuneStart(b):
+			c.emit(' ')
+		}
+		star = b == '*'
+	}
+}
+
+// emit outputs b, holding it back while a possibly trailing comma is pending.
+func (c *commentReader) emit(b byte) {
+	if c.comma {
+		c.pending = append(c.pending, b)
+		return
+	}
+	c.out = append(c.out, b)
+}
+
+// flush releases any output held back after a comma.
+func (c *commentReader) flush() {
+	c.out = append(c.out, c.pending...)
+	c.pending = c.pending[:0]
+	c.comma = false
+}
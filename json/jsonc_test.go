@@ -0,0 +1,50 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCommentReader(t *testing.T) {
+	input := "{\n  // comment, with comma\n  \"a\": \"http://x/*y*/\", /* block\n  comment */ \"b\": [1, 2,],\n}"
+	expected := "{\n                        \n  \"a\": \"http://x/*y*/\",         \n             \"b\": [1, 2 ] \n}"
+	out, err := io.ReadAll(NewCommentReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(out) != expected {
+		t.Fatalf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestCommentReader_Unterminated(t *testing.T) {
+	_, err := io.ReadAll(NewCommentReader(strings.NewReader(`{"a": 1 /* open`)))
+	if !errors.Is(err, ErrUnterminatedComment) {
+		t.Fatalf("expected ErrUnterminatedComment, got %v", err)
+	}
+}
+
+func TestLoadFileWithOpts_AllowComments(t *testing.T) {
+	filename := writeTemp(t, "config.jsonc", "{\n  // name of the service\n  \"field1\": \"test\",\n  \"field2\": 123, /* port */\n}\n")
+	if _, err := LoadFile[testStruct](filename); err == nil {
+		t.Fatalf("expected error without AllowComments")
+	}
+	result, err := LoadFileWithOpts[testStruct](filename, &LoadOpts{AllowComments: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field1 != "test" || result.Field2 != 123 {
+		t.Fatalf("expected {test 123}, got %v", result)
+	}
+}
+
+func TestLoadFileWithOpts_CommentsKeepLines(t *testing.T) {
+	filename := writeTemp(t, "config.jsonc", "{\n  /* a\n  b */\n  \"field2\": \"x\"\n}")
+	_, err := LoadFileWithOpts[testStruct](filename, &LoadOpts{AllowComments: true})
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Line != 4 || fileErr.Column != 13 {
+		t.Fatalf("expected error at 4:13, got %v", err)
+	}
+}
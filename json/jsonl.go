@@ -0,0 +1,138 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultMaxLineSize is the longest line a LineReader accepts by default.
+const defaultMaxLineSize = 16 * 1024 * 1024
+
+// LineReaderOpts defines the options for reading JSON Lines.
+type LineReaderOpts struct {
+	// SkipInvalid skips lines that fail to decode instead of returning an error.
+	SkipInvalid bool
+	// OnSkip, if set, is called with a *LineError for every skipped line.
+	OnSkip func(error)
+	// MaxLineSize is the longest line accepted. Defaults to 16MiB.
+	MaxLineSize int
+	// Load is used to decode each line.
+	Load *LoadOpts
+}
+
+// LineError reports the line of a JSON Lines stream that failed to decode.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineReader reads values of type T from a JSON Lines stream one at a time.
+type LineReader[T any] struct {
+	scanner *bufio.Scanner
+	opts    LineReaderOpts
+	line    int
+}
+
+// NewLineReader creates a new LineReader reading from r.
+func NewLineReader[T any](r io.Reader, opts *LineReaderOpts) *LineReader[T] {
+	lr := &LineReader[T]{scanner: bufio.NewScanner(r)}
+	if opts != nil {
+		lr.opts = *opts
+	}
+	if lr.opts.MaxLineSize <= 0 {
+		lr.opts.MaxLineSize = defaultMaxLineSize
+	}
+	lr.scanner.Buffer(make([]byte, 0, min(64*1024, lr.opts.MaxLineSize)), lr.opts.MaxLineSize)
+	return lr
+}
+
+// Next returns the next value, skipping blank lines. It returns io.EOF at the end of the
+// stream and a *LineError for a line that fails to decode.
+func (r *LineReader[T]) Next() (T, error) {
+	var zero T
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var v T
+		if err := decodeValue(line, &v, r.opts.Load); err != nil {
+			err = &LineError{r.line, err}
+			if !r.opts.SkipInvalid {
+				return zero, err
+			}
+			if r.opts.OnSkip != nil {
+				r.opts.OnSkip(err)
+			}
+			continue
+		}
+		return v, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return zero, &LineError{r.line + 1, err}
+	}
+	return zero, io.EOF
+}
+
+// Line returns the line number of the value last returned by Next.
+func (r *LineReader[T]) Line() int {
+	return r.line
+}
+
+// LineWriter writes values of type T as JSON Lines. It is safe for concurrent use.
+type LineWriter[T any] struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLineWriter creates a new LineWriter writing to w.
+func NewLineWriter[T any](w io.Writer) *LineWriter[T] {
+	return &LineWriter[T]{w: w}
+}
+
+// OpenLineWriter opens a JSON Lines file for appending, creating it if it does not exist.
+func OpenLineWriter[T any](filename string) (*LineWriter[T], error) {
+	file, err := os.OpenFile(filepath.Clean(filename), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewLineWriter[T](file), nil
+}
+
+// Write appends v as a single line.
+func (w *LineWriter[T]) Write(v T) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.w.Write(b)
+	return err
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (w *LineWriter[T]) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if c, ok := w.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build go1.23
+
+package json
+
+import (
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the remaining values. Iteration stops after the first error.
+func (r *LineReader[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			v, err := r.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
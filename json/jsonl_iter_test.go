@@ -0,0 +1,24 @@
+//go:build go1.23
+
+package json
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineReader_All(t *testing.T) {
+	input := "{\"field1\":\"a\"}\n{\"field1\":\"b\"}\nnot json\n{\"field1\":\"d\"}\n"
+	var result []string
+	var last error
+	for v, err := range NewLineReader[testStruct](strings.NewReader(input), nil).All() {
+		if err != nil {
+			last = err
+			continue
+		}
+		result = append(result, v.Field1)
+	}
+	if strings.Join(result, ",") != "a,b" || last == nil {
+		t.Fatalf("expected iteration to stop at the invalid line, got %v, %v", result, last)
+	}
+}
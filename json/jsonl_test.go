@@ -0,0 +1,105 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLineReader(t *testing.T) {
+	input := "{\"field1\":\"a\",\"field2\":1}\n\n{\"field1\":\"b\",\"field2\":2}\n"
+	r := NewLineReader[testStruct](strings.NewReader(input), nil)
+	var result []testStruct
+	for {
+		v, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		result = append(result, v)
+	}
+	if len(result) != 2 || result[1].Field1 != "b" || r.Line() != 3 {
+		t.Fatalf("unexpected result %v at line %d", result, r.Line())
+	}
+}
+
+func TestLineReader_Invalid(t *testing.T) {
+	input := "{\"field1\":\"a\"}\n{\"field2\":\"x\"}\n{\"field1\":\"c\"}\n"
+	r := NewLineReader[testStruct](strings.NewReader(input), nil)
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err := r.Next()
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Fatalf("expected *LineError on line 2, got %v", err)
+	}
+
+	var skipped []error
+	r = NewLineReader[testStruct](strings.NewReader(input), &LineReaderOpts{
+		SkipInvalid: true,
+		OnSkip:      func(err error) { skipped = append(skipped, err) },
+	})
+	count := 0
+	for {
+		if _, err = r.Next(); err != nil {
+			break
+		}
+		count++
+	}
+	if err != io.EOF || count != 2 || len(skipped) != 1 {
+		t.Fatalf("expected 2 values and 1 skipped line, got %d, %d, %v", count, len(skipped), err)
+	}
+}
+
+func TestLineReader_MaxLineSize(t *testing.T) {
+	r := NewLineReader[testStruct](strings.NewReader(`{"field1":"`+strings.Repeat("a", 100)+`"}`), &LineReaderOpts{MaxLineSize: 64})
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Fatalf("expected error for long line, got %v", err)
+	}
+}
+
+func TestLineWriter_Concurrent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "events.jsonl")
+	w, err := OpenLineWriter[testStruct](filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := w.Write(testStruct{Field1: strings.Repeat("x", 1000), Field2: i}); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err = w.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, _ := os.ReadFile(filename)
+	r := NewLineReader[testStruct](bytes.NewReader(data), nil)
+	seen := make(map[int]bool)
+	for {
+		v, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		seen[v.Field2] = true
+	}
+	if len(seen) != 100 {
+		t.Fatalf("expected 100 distinct values, got %d", len(seen))
+	}
+}
@@ -0,0 +1,296 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Layer is a configuration file applied over the layers before it.
+type Layer struct {
+	// Name identifies the layer in Sources. Defaults to File.
+	Name string
+	File string
+	// Optional skips the layer when the file does not exist.
+	Optional bool
+}
+
+// MapStrategy defines how an object in a layer combines with the object below it.
+type MapStrategy int
+
+// Map strategies
+const (
+	// MapMerge merges objects key by key, recursively.
+	MapMerge MapStrategy = iota
+	// MapReplace replaces nested objects wholesale. The top-level objects of layers are
+	// always merged.
+	MapReplace
+)
+
+// ArrayStrategy defines how an array in a layer combines with the array below it.
+type ArrayStrategy int
+
+// Array strategies
+const (
+	// ArrayReplace replaces the array wholesale.
+	ArrayReplace ArrayStrategy = iota
+	// ArrayAppend appends the elements to the array below.
+	ArrayAppend
+	// ArrayMergeIndex merges elements with the same index, appending any extra elements.
+	ArrayMergeIndex
+)
+
+// LayerOpts defines the options for loading layered configuration.
+type LayerOpts struct {
+	Maps   MapStrategy
+	Arrays ArrayStrategy
+	// EnvPrefix applies environment variables starting with the prefix as a final layer.
+	// Nested keys are separated by a double underscore, so APP_SERVER__PORT=8080 sets
+	// server.port. Values are parsed as JSON, falling back to a string when they are not
+	// valid JSON or the parsed value does not fit the field.
+	EnvPrefix string
+	// Load is used to read each layer and to decode the merged result.
+	Load *LoadOpts
+}
+
+// Sources maps the JSON Pointer of every value in a merged configuration to the name of
+// the layer it came from. Environment overrides are named after their variable.
+type Sources map[string]string
+
+// LoadLayers deep-merges layers in order into a new struct and reports where each value came from.
+func LoadLayers[T any](layers []Layer, opts *LayerOpts) (*T, Sources, error) {
+	if opts == nil {
+		opts = &LayerOpts{}
+	}
+	m := &merger{opts: opts, sources: make(Sources), raw: make(map[string]string)}
+	var tree any
+	for _, layer := range layers {
+		v, err := readLayer(layer.File, opts.Load)
+		if err != nil {
+			if layer.Optional && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, nil, err
+		}
+		m.layer = layer.Name
+		if m.layer == "" {
+			m.layer = layer.File
+		}
+		tree = m.merge(tree, v, "")
+	}
+	if opts.EnvPrefix != "" {
+		tree = m.applyEnv(tree, opts.EnvPrefix)
+	}
+	for {
+		data, err := json.Marshal(tree)
+		if err != nil {
+			return nil, nil, err
+		}
+		var s T
+		err = decode("", data, &s, opts.Load)
+		if err == nil {
+			return &s, m.sources, nil
+		}
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) || fileErr.Path == "" {
+			return nil, nil, err
+		}
+		// an environment value such as 123 parsed as JSON may be meant as a string
+		p := fileErr.pointer
+		if raw, ok := m.raw[p]; ok {
+			delete(m.raw, p)
+			ptr, err := ParsePointer(p)
+			if err == nil {
+				tree, err = ptr.Set(tree, raw)
+			}
+			if err == nil {
+				continue
+			}
+		}
+		return nil, nil, fmt.Errorf("%s (from %s): %w", fileErr.Path, m.sources.lookup(p), fileErr.Err)
+	}
+}
+
+// readLayer loads a layer file as a generic tree.
+func readLayer(filename string, opts *LoadOpts) (any, error) {
+	o := LoadOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	o.UseNumber = true
+	data, err := readFile(filename, &o)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err = load(filename, data, &v, &o); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// merger deep-merges layers while recording the source of each value.
+type merger struct {
+	opts    *LayerOpts
+	layer   string
+	sources Sources
+	// raw holds the original text of environment values that were parsed as JSON.
+	raw map[string]string
+}
+
+// merge combines src over dst at path and returns the result.
+func (m *merger) merge(dst, src any, path string) any {
+	switch s := src.(type) {
+	case map[string]any:
+		d, ok := dst.(map[string]any)
+		// the strategy applies to nested objects; layers always merge at the root
+		if !ok || (m.opts.Maps == MapReplace && path != "") {
+			m.replace(path, s)
+			return s
+		}
+		for k, v := range s {
+			d[k] = m.merge(d[k], v, path+"/"+escapeToken(k))
+		}
+		if len(s) == 0 && len(d) == 0 {
+			m.sources[path] = m.layer
+		}
+		return d
+	case []any:
+		d, ok := dst.([]any)
+		if !ok || m.opts.Arrays == ArrayReplace {
+			m.replace(path, s)
+			return s
+		}
+		for i, v := range s {
+			p := path + "/" + strconv.Itoa(len(d))
+			if m.opts.Arrays == ArrayMergeIndex && i < len(d) {
+				p = path + "/" + strconv.Itoa(i)
+				d[i] = m.merge(d[i], v, p)
+				continue
+			}
+			m.record(p, v)
+			d = append(d, v)
+		}
+		return d
+	default:
+		m.replace(path, src)
+		return src
+	}
+}
+
+// replace forgets the sources of the value at path and records v in its place.
+func (m *merger) replace(path string, v any) {
+	m.forget(path)
+	m.record(path, v)
+}
+
+// forget removes the sources of the value at path.
+func (m *merger) forget(path string) {
+	for p := range m.sources {
+		if p == path || strings.HasPrefix(p, path+"/") {
+			delete(m.sources, p)
+		}
+	}
+}
+
+// record attributes every leaf of v to the current layer.
+func (m *merger) record(path string, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			m.sources[path] = m.layer
+		}
+		for k, child := range v {
+			m.record(path+"/"+escapeToken(k), child)
+		}
+	case []any:
+		if len(v) == 0 {
+			m.sources[path] = m.layer
+		}
+		for i, child := range v {
+			m.record(path+"/"+strconv.Itoa(i), child)
+		}
+	default:
+		m.sources[path] = m.layer
+	}
+}
+
+// applyEnv sets values from environment variables with the given prefix.
+func (m *merger) applyEnv(tree any, prefix string) any {
+	env := os.Environ()
+	sort.Strings(env)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		var v any
+		dec := json.NewDecoder(strings.NewReader(value))
+		dec.UseNumber()
+		raw := ""
+		if dec.Decode(&v) != nil || dec.More() {
+			v = value
+		} else if _, ok := v.(string); !ok {
+			raw = value
+		}
+		m.layer = name
+		tree = m.set(tree, strings.Split(rest, "__"), v, raw, "")
+	}
+	return tree
+}
+
+// set replaces the value at the path of keys, matching existing keys case-insensitively.
+// raw is the original text of v when it was parsed from JSON.
+func (m *merger) set(tree any, keys []string, v any, raw, path string) any {
+	if len(keys) == 0 {
+		m.replace(path, v)
+		if raw != "" {
+			m.raw[path] = raw
+		}
+		return v
+	}
+	obj, ok := tree.(map[string]any)
+	if !ok {
+		m.forget(path)
+		obj = make(map[string]any)
+	}
+	key := strings.ToLower(keys[0])
+	for k := range obj {
+		if strings.EqualFold(k, keys[0]) {
+			key = k
+			break
+		}
+	}
+	obj[key] = m.set(obj[key], keys[1:], v, raw, path+"/"+escapeToken(key))
+	return obj
+}
+
+// lookup returns the layer of the value at the JSON Pointer p.
+func (s Sources) lookup(p string) string {
+	for {
+		if layer, ok := s[p]; ok {
+			return layer
+		}
+		for k, layer := range s {
+			if strings.HasPrefix(k, p+"/") {
+				return layer
+			}
+		}
+		i := strings.LastIndexByte(p, '/')
+		if i <= 0 {
+			return "unknown layer"
+		}
+		p = p[:i]
+	}
+}
+
+// escapeToken escapes a key for use in a JSON Pointer.
+func escapeToken(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,123 @@
+package json
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testLayered struct {
+	Name   string            `json:"name"`
+	Server testServer        `json:"server"`
+	Tags   []string          `json:"tags"`
+	Limits map[string]int    `json:"limits"`
+	Labels map[string]string `json:"labels"`
+}
+
+func TestLoadLayers(t *testing.T) {
+	base := writeTemp(t, "base.json", `{"name":"svc","server":{"port":80},"tags":["a"],"limits":{"cpu":1,"mem":2}}`)
+	prod := writeTemp(t, "prod.json", `{"tags":["b"],"limits":{"mem":4}}`)
+	t.Setenv("TEST_LAYERS_SERVER__PORT", "8080")
+	t.Setenv("TEST_LAYERS_LABELS__TEAM", "core")
+	result, sources, err := LoadLayers[testLayered]([]Layer{
+		{Name: "base", File: base},
+		{Name: "prod", File: prod},
+		{Name: "local", File: filepath.Join(t.TempDir(), "missing.json"), Optional: true},
+	}, &LayerOpts{Arrays: ArrayAppend, EnvPrefix: "TEST_LAYERS_"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := testLayered{
+		Name:   "svc",
+		Server: testServer{Port: 8080},
+		Tags:   []string{"a", "b"},
+		Limits: map[string]int{"cpu": 1, "mem": 4},
+		Labels: map[string]string{"team": "core"},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, *result)
+	}
+	expectedSources := Sources{
+		"/name":        "base",
+		"/server/port": "TEST_LAYERS_SERVER__PORT",
+		"/tags/0":      "base",
+		"/tags/1":      "prod",
+		"/limits/cpu":  "base",
+		"/limits/mem":  "prod",
+		"/labels/team": "TEST_LAYERS_LABELS__TEAM",
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Fatalf("expected sources %v, got %v", expectedSources, sources)
+	}
+}
+
+func TestLoadLayers_Replace(t *testing.T) {
+	base := writeTemp(t, "base.json", `{"name":"svc","tags":["a","b"],"limits":{"cpu":1,"mem":2}}`)
+	override := writeTemp(t, "override.json", `{"tags":["c"],"limits":{"mem":4}}`)
+	result, sources, err := LoadLayers[testLayered]([]Layer{{File: base}, {File: override}}, &LayerOpts{Maps: MapReplace})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Name != "svc" {
+		t.Fatalf("expected top-level object to be merged, got %+v", *result)
+	}
+	if !reflect.DeepEqual(result.Tags, []string{"c"}) || !reflect.DeepEqual(result.Limits, map[string]int{"mem": 4}) {
+		t.Fatalf("expected replaced values, got %+v", *result)
+	}
+	expectedSources := Sources{"/name": base, "/tags/0": override, "/limits/mem": override}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Fatalf("expected sources %v, got %v", expectedSources, sources)
+	}
+}
+
+func TestLoadLayers_EnvString(t *testing.T) {
+	base := writeTemp(t, "base.json", `{"name":"svc","server":{"port":80}}`)
+	t.Setenv("TEST_LAYERS_NAME", "123")
+	t.Setenv("TEST_LAYERS_LABELS__ENABLED", "true")
+	t.Setenv("TEST_LAYERS_SERVER__PORT", "8080")
+	result, _, err := LoadLayers[testLayered]([]Layer{{File: base}}, &LayerOpts{EnvPrefix: "TEST_LAYERS_"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Name != "123" || result.Labels["enabled"] != "true" || result.Server.Port != 8080 {
+		t.Fatalf("expected raw strings for string fields, got %+v", *result)
+	}
+	t.Setenv("TEST_LAYERS_SERVER__PORT", "high")
+	if _, _, err = LoadLayers[testLayered]([]Layer{{File: base}}, &LayerOpts{EnvPrefix: "TEST_LAYERS_"}); err == nil || !strings.Contains(err.Error(), "TEST_LAYERS_SERVER__PORT") {
+		t.Fatalf("expected error naming the variable, got %v", err)
+	}
+}
+
+func TestLoadLayers_TypeErrorNamesLayer(t *testing.T) {
+	base := writeTemp(t, "base.json", `{"server":{"port":80}}`)
+	local := writeTemp(t, "local.json", `{"server":{"port":"80"}}`)
+	_, _, err := LoadLayers[testLayered]([]Layer{{Name: "base", File: base}, {Name: "local", File: local}}, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "server.port (from local): ") {
+		t.Fatalf("expected error naming the layer, got %v", err)
+	}
+}
+
+func TestLoadLayers_EscapedKeys(t *testing.T) {
+	base := writeTemp(t, "base.json", `{"limits":{"a":1,"a.b/c":2}}`)
+	local := writeTemp(t, "local.json", `{"limits":{"a.b/c":"x"}}`)
+	_, _, err := LoadLayers[testLayered]([]Layer{{Name: "base", File: base}, {Name: "local", File: local}}, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "limits.a.b/c (from local): ") {
+		t.Fatalf("expected error naming the layer, got %v", err)
+	}
+	t.Setenv("TEST_LAYERS_LABELS__A.B~C", "123")
+	result, _, err := LoadLayers[testLayered]([]Layer{{File: base}}, &LayerOpts{EnvPrefix: "TEST_LAYERS_"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Labels["a.b~c"] != "123" {
+		t.Fatalf("expected raw string for dotted key, got %+v", *result)
+	}
+}
+
+func TestLoadLayers_MissingRequired(t *testing.T) {
+	_, _, err := LoadLayers[testLayered]([]Layer{{File: filepath.Join(t.TempDir(), "missing.json")}}, nil)
+	if err == nil {
+		t.Fatalf("expected error for missing required layer")
+	}
+}
@@ -0,0 +1,167 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LoadOpts defines the options for loading a json file.
+type LoadOpts struct {
+	// DisallowUnknownFields rejects object keys that do not match a field of the destination.
+	DisallowUnknownFields bool
+	// DisallowTrailingData rejects anything but whitespace after the first JSON value.
+	DisallowTrailingData bool
+	// UseNumber decodes numbers into interface values as json.Number instead of float64.
+	UseNumber bool
+	// MaxSize rejects files larger than MaxSize bytes, after decompression. Zero means no limit.
+	MaxSize int64
+	// AllowComments accepts // and /* */ comments and trailing commas.
+	AllowComments bool
+	// Resolver, if set, expands ${...} references in string values before decoding.
+	// See EnvResolver.
+	Resolver Resolver
+	// Validate applies `default` tags and checks `validate` tags after decoding.
+	// See SetDefaults and Validate.
+	Validate bool
+}
+
+type (
+	// TooLargeError is returned when a file exceeds LoadOpts.MaxSize.
+	TooLargeError struct {
+		MaxSize int64
+	}
+	// TrailingDataError is returned when data follows the first JSON value and
+	// LoadOpts.DisallowTrailingData is set.
+	TrailingDataError struct {
+		Offset int64
+	}
+)
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("file exceeds maximum size of %d bytes", e.MaxSize)
+}
+
+func (e *TrailingDataError) Error() string {
+	return fmt.Sprintf("unexpected data after top-level value at offset %d", e.Offset)
+}
+
+// LoadFileWithOpts loads a json file into a new struct with the specified options.
+func LoadFileWithOpts[T any](filename string, opts *LoadOpts) (*T, error) {
+	var s T
+	if err := MergeFileWithOpts(filename, &s, opts); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MergeFileWithOpts loads a json file into an existing struct with the specified options.
+func MergeFileWithOpts(filename string, s any, opts *LoadOpts) error {
+	data, err := readFile(filename, opts)
+	if err != nil {
+		return err
+	}
+	return load(filename, data, s, opts)
+}
+
+// readFile reads a whole file, decompressing it and enforcing the size limit.
+func readFile(filename string, opts *LoadOpts) ([]byte, error) {
+	file, err := os.Open(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return read(file, filename, opts)
+}
+
+// read reads r to the end, decompressing it and enforcing the size limit.
+func read(r io.Reader, name string, opts *LoadOpts) ([]byte, error) {
+	r, err := decompress(r, name)
+	if err != nil {
+		return nil, err
+	}
+	return readAll(r, opts)
+}
+
+// readAll reads r to the end, enforcing the size limit.
+func readAll(r io.Reader, opts *LoadOpts) ([]byte, error) {
+	if opts == nil || opts.MaxSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, opts.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > opts.MaxSize {
+		return nil, &TooLargeError{opts.MaxSize}
+	}
+	return data, nil
+}
+
+// load preprocesses and decodes data read from name into s.
+func load(name string, data []byte, s any, opts *LoadOpts) error {
+	if opts != nil && opts.AllowComments {
+		var err error
+		if data, err = io.ReadAll(NewCommentReader(bytes.NewReader(data))); err != nil {
+			return locate(name, data, err)
+		}
+	}
+	if opts != nil && opts.Resolver != nil {
+		expanded, err := Expand(data, opts.Resolver)
+		if err != nil {
+			return locate(name, data, err)
+		}
+		// resolved values may be secrets and shift columns, so only the line is reported
+		err = decode(name, expanded, s, opts)
+		var fileErr *FileError
+		if errors.As(err, &fileErr) {
+			fileErr.Column, fileErr.Snippet = 0, ""
+		}
+		return err
+	}
+	return decode(name, data, s, opts)
+}
+
+// decode decodes data into s with the specified options. Errors are reported as a
+// *FileError locating them in name.
+func decode(name string, data []byte, s any, opts *LoadOpts) error {
+	if err := decodeValue(data, s, opts); err != nil {
+		return locate(name, data, err)
+	}
+	return nil
+}
+
+// decodeValue decodes data into s with the specified options.
+func decodeValue(data []byte, s any, opts *LoadOpts) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if opts != nil {
+		if opts.DisallowUnknownFields {
+			dec.DisallowUnknownFields()
+		}
+		if opts.UseNumber {
+			dec.UseNumber()
+		}
+	}
+	if err := dec.Decode(s); err != nil {
+		return err
+	}
+	if opts != nil && opts.DisallowTrailingData {
+		offset := dec.InputOffset()
+		if _, err := dec.Token(); err != io.EOF {
+			return &TrailingDataError{offset}
+		}
+	}
+	if opts != nil && opts.Validate {
+		if err := SetDefaults(s); err != nil {
+			return err
+		}
+		if err := Validate(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTemp writes content to a temporary file and returns its name.
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return filename
+}
+
+func TestLoadFileWithOpts_UnknownFields(t *testing.T) {
+	filename := writeTemp(t, "test.json", `{"field1":"test","feild2":1}`)
+	if _, err := LoadFileWithOpts[testStruct](filename, nil); err != nil {
+		t.Fatalf("expected unknown field to be ignored by default, got %v", err)
+	}
+	_, err := LoadFileWithOpts[testStruct](filename, &LoadOpts{DisallowUnknownFields: true})
+	if err == nil || !strings.Contains(err.Error(), `"feild2"`) {
+		t.Fatalf("expected error naming the unknown field, got %v", err)
+	}
+}
+
+func TestLoadFileWithOpts_TrailingData(t *testing.T) {
+	filename := writeTemp(t, "test.json", "{\"field1\":\"test\"}\n}garbage")
+	if _, err := LoadFileWithOpts[testStruct](filename, nil); err != nil {
+		t.Fatalf("expected trailing data to be ignored by default, got %v", err)
+	}
+	_, err := LoadFileWithOpts[testStruct](filename, &LoadOpts{DisallowTrailingData: true})
+	var trailing *TrailingDataError
+	if !errors.As(err, &trailing) {
+		t.Fatalf("expected TrailingDataError, got %v", err)
+	}
+	filename = writeTemp(t, "test.json", "{\"field1\":\"test\"}\n\n")
+	if _, err = LoadFileWithOpts[testStruct](filename, &LoadOpts{DisallowTrailingData: true}); err != nil {
+		t.Fatalf("expected trailing whitespace to be allowed, got %v", err)
+	}
+}
+
+func TestLoadFileWithOpts_UseNumber(t *testing.T) {
+	filename := writeTemp(t, "test.json", `{"id":9007199254740993}`)
+	result, err := LoadFileWithOpts[map[string]any](filename, &LoadOpts{UseNumber: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n, ok := (*result)["id"].(json.Number); !ok || n.String() != "9007199254740993" {
+		t.Fatalf("expected exact json.Number, got %v", (*result)["id"])
+	}
+}
+
+func TestLoadFileWithOpts_MaxSize(t *testing.T) {
+	filename := writeTemp(t, "test.json", `{"field1":"test"}`)
+	_, err := LoadFileWithOpts[testStruct](filename, &LoadOpts{MaxSize: 8})
+	var tooLarge *TooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected TooLargeError, got %v", err)
+	}
+	if _, err = LoadFileWithOpts[testStruct](filename, &LoadOpts{MaxSize: 17}); err != nil {
+		t.Fatalf("expected no error at the limit, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package json
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on filename.lock, blocking until it is available.
+func lockFile(filename string) (func(), error) {
+	file, err := os.OpenFile(filename+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, &os.PathError{Op: "flock", Path: file.Name(), Err: err}
+	}
+	return func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		_ = file.Close()
+	}, nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package json
+
+import "sync"
+
+// locks holds a mutex per file for platforms without flock support.
+var locks sync.Map
+
+// lockFile locks filename against other callers in the same process.
+func lockFile(filename string) (func(), error) {
+	mu, _ := locks.LoadOrStore(filename, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock, nil
+}
@@ -0,0 +1,130 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Migration upgrades a decoded document by one version.
+type Migration func(doc map[string]any) error
+
+// Migrator upgrades json documents to the current version step by step with registered migrations.
+type Migrator struct {
+	// Field is the name of the version field. Defaults to "version".
+	// Documents without it are version 0.
+	Field      string
+	current    int
+	migrations map[int]Migration
+}
+
+// MigrateOpts defines the options for loading a versioned json file.
+type MigrateOpts struct {
+	// WriteBack saves a migrated file over the original, keeping the previous version
+	// as filename.bak. References expanded by Load.Resolver are not written back.
+	WriteBack bool
+	// Load is used to read the file and decode the migrated document.
+	Load *LoadOpts
+}
+
+// NewMigrator creates a new Migrator for documents whose current version is current.
+func NewMigrator(current int) *Migrator {
+	return &Migrator{
+		Field:      "version",
+		current:    current,
+		migrations: make(map[int]Migration),
+	}
+}
+
+// Register adds the migration that upgrades a document from version from to from+1.
+func (m *Migrator) Register(from int, fn Migration) {
+	m.migrations[from] = fn
+}
+
+// Migrate upgrades a document in place to the current version and reports whether it changed.
+// The document must not be nil.
+func (m *Migrator) Migrate(doc map[string]any) (bool, error) {
+	if doc == nil {
+		return false, errors.New("document is not an object")
+	}
+	version, err := m.version(doc)
+	if err != nil {
+		return false, err
+	}
+	if version > m.current {
+		return false, fmt.Errorf("document version %d is newer than supported version %d", version, m.current)
+	}
+	changed := false
+	for ; version < m.current; version++ {
+		fn, ok := m.migrations[version]
+		if !ok {
+			return changed, fmt.Errorf("no migration registered from version %d", version)
+		}
+		if err = fn(doc); err != nil {
+			return changed, fmt.Errorf("migrating from version %d: %w", version, err)
+		}
+		doc[m.Field] = version + 1
+		changed = true
+	}
+	return changed, nil
+}
+
+// version reads the version field of a document.
+func (m *Migrator) version(doc map[string]any) (int, error) {
+	v, ok := doc[m.Field]
+	if !ok {
+		return 0, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), nil
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return int(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid %s field %v", m.Field, v)
+}
+
+// LoadMigrated loads a versioned json file into a new struct, migrating it to the current version first.
+func LoadMigrated[T any](filename string, m *Migrator, opts *MigrateOpts) (*T, error) {
+	if opts == nil {
+		opts = &MigrateOpts{}
+	}
+	raw := LoadOpts{UseNumber: true}
+	if opts.Load != nil {
+		raw.MaxSize = opts.Load.MaxSize
+		raw.AllowComments = opts.Load.AllowComments
+	}
+	data, err := readFile(filename, &raw)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err = load(filename, data, &doc, &raw); err != nil {
+		return nil, err
+	}
+	changed, err := m.Migrate(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if changed {
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	var s T
+	if err = load(filename, data, &s, opts.Load); err != nil {
+		return nil, err
+	}
+	if changed && opts.WriteBack {
+		if err = SaveFileWithOpts(filename, doc, &SaveOpts{Atomic: true, Backup: true}); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
@@ -0,0 +1,93 @@
+package json
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type testVersioned struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Port    int    `json:"port"`
+}
+
+// testMigrator renames "title" to "name" in version 1 and adds a port in version 2.
+func testMigrator() *Migrator {
+	m := NewMigrator(2)
+	m.Register(0, func(doc map[string]any) error {
+		doc["name"] = doc["title"]
+		delete(doc, "title")
+		return nil
+	})
+	m.Register(1, func(doc map[string]any) error {
+		doc["port"] = 8080
+		return nil
+	})
+	return m
+}
+
+func TestLoadMigrated(t *testing.T) {
+	filename := writeTemp(t, "config.json", `{"title":"svc"}`)
+	result, err := LoadMigrated[testVersioned](filename, testMigrator(), &MigrateOpts{WriteBack: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := testVersioned{Version: 2, Name: "svc", Port: 8080}
+	if *result != expected {
+		t.Fatalf("expected %v, got %v", expected, *result)
+	}
+	saved, _ := os.ReadFile(filename)
+	if strings.TrimSpace(string(saved)) != `{"name":"svc","port":8080,"version":2}` {
+		t.Fatalf("expected migrated file to be written back, got %s", saved)
+	}
+	backup, _ := os.ReadFile(filename + ".bak")
+	if string(backup) != `{"title":"svc"}` {
+		t.Fatalf("expected backup of the original, got %s", backup)
+	}
+}
+
+func TestLoadMigrated_Current(t *testing.T) {
+	filename := writeTemp(t, "config.json", `{"version":2,"name":"svc","port":1}`)
+	result, err := LoadMigrated[testVersioned](filename, testMigrator(), &MigrateOpts{WriteBack: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Port != 1 {
+		t.Fatalf("expected document to be left alone, got %v", result)
+	}
+	if _, err = os.Stat(filename + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup when nothing was migrated")
+	}
+}
+
+func TestMigrator_Errors(t *testing.T) {
+	m := testMigrator()
+	for _, doc := range []map[string]any{
+		{"version": 3},
+		{"version": "1"},
+		{"version": 1.5},
+	} {
+		if _, err := m.Migrate(doc); err == nil {
+			t.Fatalf("expected error for %v", doc)
+		}
+	}
+	failing := NewMigrator(1)
+	failing.Register(0, func(map[string]any) error { return fmt.Errorf("boom") })
+	if _, err := failing.Migrate(map[string]any{}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected migration error, got %v", err)
+	}
+	if _, err := NewMigrator(1).Migrate(map[string]any{}); err == nil {
+		t.Fatalf("expected error for missing migration")
+	}
+	if _, err := m.Migrate(nil); err == nil {
+		t.Fatalf("expected error for nil document")
+	}
+	for _, content := range []string{"null", "[1]"} {
+		filename := writeTemp(t, "config.json", content)
+		if _, err := LoadMigrated[testStruct](filename, m, nil); err == nil {
+			t.Fatalf("expected error for %s document", content)
+		}
+	}
+}
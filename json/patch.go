@@ -0,0 +1,303 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value any
+}
+
+// Patch is an RFC 6902 JSON Patch document.
+type Patch []Operation
+
+// MarshalJSON implements json.Marshaler, including value only for operations that use it.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	m := map[string]any{"op": o.Op, "path": o.Path}
+	switch o.Op {
+	case "add", "replace", "test":
+		m["value"] = o.Value
+	case "move", "copy":
+		m["from"] = o.From
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, requiring the members each operation uses.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	var op Operation
+	for _, member := range []struct {
+		name string
+		dst  *string
+	}{{"op", &op.Op}, {"path", &op.Path}} {
+		raw, ok := m[member.name]
+		if !ok {
+			return fmt.Errorf("patch operation missing %q", member.name)
+		}
+		if err := json.Unmarshal(raw, member.dst); err != nil {
+			return err
+		}
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		raw, ok := m["value"]
+		if !ok {
+			return fmt.Errorf("%s operation missing \"value\"", op.Op)
+		}
+		if err := decodeValue(raw, &op.Value, &LoadOpts{UseNumber: true}); err != nil {
+			return err
+		}
+	case "move", "copy":
+		raw, ok := m["from"]
+		if !ok {
+			return fmt.Errorf("%s operation missing \"from\"", op.Op)
+		}
+		if err := json.Unmarshal(raw, &op.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown patch operation %q", op.Op)
+	}
+	*o = op
+	return nil
+}
+
+// ApplyPatch applies a JSON Patch to a decoded document and returns the result. The
+// document is not modified, and no operation is applied if any of them fails.
+func ApplyPatch(doc any, patch Patch) (any, error) {
+	doc = deepCopy(doc)
+	for i, op := range patch {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// apply applies a single operation.
+func (o Operation) apply(doc any) (any, error) {
+	path, err := ParsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch o.Op {
+	case "add":
+		return path.Set(doc, deepCopy(o.Value))
+	case "remove":
+		return path.Remove(doc)
+	case "replace":
+		return path.Replace(doc, deepCopy(o.Value))
+	case "move", "copy":
+		from, err := ParsePointer(o.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := from.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if o.Op == "copy" {
+			return path.Set(doc, deepCopy(v))
+		}
+		if o.Path != o.From && strings.HasPrefix(o.Path, o.From+"/") {
+			return nil, fmt.Errorf("cannot move a value into one of its children")
+		}
+		if doc, err = from.Remove(doc); err != nil {
+			return nil, err
+		}
+		return path.Set(doc, v)
+	case "test":
+		v, err := path.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !Equal(v, o.Value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown patch operation %q", o.Op)
+	}
+}
+
+// MergePatch applies an RFC 7396 JSON Merge Patch to a decoded document and returns the
+// result. The document is not modified.
+func MergePatch(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return deepCopy(patch)
+	}
+	target, ok := deepCopy(doc).(map[string]any)
+	if !ok {
+		target = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+		target[k] = MergePatch(target[k], v)
+	}
+	return target
+}
+
+// CreateMergePatch returns the JSON Merge Patch that turns original into modified.
+func CreateMergePatch(original, modified any) any {
+	o, ok1 := original.(map[string]any)
+	m, ok2 := modified.(map[string]any)
+	if !ok1 || !ok2 {
+		return deepCopy(modified)
+	}
+	patch := make(map[string]any)
+	for k := range o {
+		if _, ok := m[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, v := range m {
+		ov, ok := o[k]
+		switch {
+		case !ok:
+			patch[k] = deepCopy(v)
+		case Equal(ov, v):
+		default:
+			_, isObj := v.(map[string]any)
+			if _, wasObj := ov.(map[string]any); isObj && wasObj {
+				patch[k] = CreateMergePatch(ov, v)
+			} else {
+				patch[k] = deepCopy(v)
+			}
+		}
+	}
+	return patch
+}
+
+// PatchFile applies a JSON Patch to a json file in place.
+func PatchFile(filename string, patch Patch) error {
+	return updateDoc(filename, func(doc any) (any, error) {
+		return ApplyPatch(doc, patch)
+	})
+}
+
+// MergePatchFile applies a JSON Merge Patch to a json file in place.
+func MergePatchFile(filename string, patch any) error {
+	return updateDoc(filename, func(doc any) (any, error) {
+		return MergePatch(doc, patch), nil
+	})
+}
+
+// updateDoc loads a json file as a generic document, transforms it and saves it atomically.
+func updateDoc(filename string, fn func(any) (any, error)) error {
+	doc, err := LoadFileWithOpts[any](filename, &LoadOpts{UseNumber: true})
+	if err != nil {
+		return err
+	}
+	updated, err := fn(*doc)
+	if err != nil {
+		return err
+	}
+	return SaveFileWithOpts(filename, updated, &SaveOpts{Atomic: true})
+}
+
+// Equal reports whether two decoded JSON values are equal. Numbers are compared by value,
+// whether decoded as float64 or json.Number. Two json.Numbers are compared exactly.
+func Equal(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			bv, ok := b[k]
+			if !ok || !Equal(v, bv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !Equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case float64, json.Number:
+		if x, ok := exact(a); ok {
+			if y, ok := exact(b); ok {
+				return x.Cmp(y) == 0
+			}
+		}
+		x, ok1 := number(a)
+		y, ok2 := number(b)
+		return ok1 && ok2 && x == y
+	default:
+		return a == b
+	}
+}
+
+// number converts a decoded JSON number to a float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// exact converts a json.Number to an exact rational. Exponents beyond the float64 range are
+// not expanded, as the result could be very large.
+func exact(v any) (*big.Rat, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	if i := strings.IndexAny(string(n), "eE"); i >= 0 {
+		if exp, err := strconv.Atoi(string(n[i+1:])); err != nil || exp < -400 || exp > 400 {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(string(n))
+}
+
+// deepCopy copies a decoded JSON value so it can be modified independently.
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, child := range v {
+			m[k] = deepCopy(child)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, child := range v {
+			s[i] = deepCopy(child)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,115 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	cases := []struct {
+		doc, patch, expected string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"bar":[1]}}`, `[{"op":"copy","from":"/foo/bar","path":"/baz"}]`, `{"foo":{"bar":[1]},"baz":[1]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/n","value":null}]`, `{"foo":"bar","n":null}`},
+	}
+	for _, c := range cases {
+		var patch Patch
+		if err := json.Unmarshal([]byte(c.patch), &patch); err != nil {
+			t.Fatalf("%s: expected no error, got %v", c.patch, err)
+		}
+		doc := decodeDoc(t, c.doc)
+		result, err := ApplyPatch(doc, patch)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", c.patch, err)
+		}
+		if !Equal(result, decodeDoc(t, c.expected)) {
+			t.Fatalf("%s: expected %s, got %v", c.patch, c.expected, result)
+		}
+		if !Equal(doc, decodeDoc(t, c.doc)) {
+			t.Fatalf("%s: expected original document to be unchanged, got %v", c.patch, doc)
+		}
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	for _, p := range []string{
+		`[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+		`[{"op":"test","path":"/baz","value":"bar"}]`,
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"move","from":"/baz","path":"/baz/child"}]`,
+		`[{"op":"replace","path":"/foo"}]`,
+		`[{"op":"frobnicate","path":"/foo"}]`,
+	} {
+		var patch Patch
+		err := json.Unmarshal([]byte(p), &patch)
+		if err == nil {
+			_, err = ApplyPatch(decodeDoc(t, `{"baz":"qux","foo":"bar"}`), patch)
+		}
+		if err == nil {
+			t.Fatalf("%s: expected error", p)
+		}
+	}
+}
+
+func TestPatch_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(Patch{{Op: "add", Path: "/a", Value: nil}, {Op: "remove", Path: "/b"}, {Op: "copy", From: "/a", Path: "/c"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"from":"/a","op":"copy","path":"/c"}]`
+	if string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		doc, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		doc := decodeDoc(t, c.doc)
+		result := MergePatch(doc, decodeDoc(t, c.patch))
+		if !Equal(result, decodeDoc(t, c.expected)) {
+			t.Fatalf("%s + %s: expected %s, got %v", c.doc, c.patch, c.expected, result)
+		}
+		created := CreateMergePatch(doc, result)
+		if !Equal(MergePatch(doc, created), result) {
+			t.Fatalf("%s: created patch %v does not reproduce %v", c.doc, created, result)
+		}
+	}
+}
+
+func TestPatchFile(t *testing.T) {
+	filename := writeTemp(t, "test.json", `{"field1":"test","field2":123}`)
+	err := PatchFile(filename, Patch{{Op: "replace", Path: "/field2", Value: 456}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = MergePatchFile(filename, map[string]any{"field1": "merged"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	result, err := LoadFile[testStruct](filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field1 != "merged" || result.Field2 != 456 {
+		t.Fatalf("expected {merged 456}, got %v", result)
+	}
+}
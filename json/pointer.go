@@ -0,0 +1,174 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer is a parsed RFC 6901 JSON Pointer.
+type Pointer []string
+
+// ParsePointer parses a JSON Pointer such as /servers/0/port.
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(token, "~0", ""), "~1", ""), "~") {
+			return nil, fmt.Errorf("invalid JSON pointer %q: bad escape in %q", s, token)
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// String returns the pointer in its escaped form.
+func (p Pointer) String() string {
+	var sb strings.Builder
+	for _, token := range p {
+		sb.WriteString("/")
+		sb.WriteString(escapeToken(token))
+	}
+	return sb.String()
+}
+
+// Get returns the value at the pointer in a decoded document.
+func (p Pointer) Get(doc any) (any, error) {
+	v := doc
+	for i, token := range p {
+		switch node := v.(type) {
+		case map[string]any:
+			child, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%s: key not found", p[:i+1])
+			}
+			v = child
+		case []any:
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p[:i+1], err)
+			}
+			v = node[index]
+		default:
+			return nil, fmt.Errorf("%s: cannot index %T", p[:i+1], v)
+		}
+	}
+	return v, nil
+}
+
+// Set sets the value at the pointer and returns the updated document. Objects get the key
+// added or replaced in place, and arrays get the value inserted at the index or appended
+// for "-" in a new slice, leaving the original array unchanged.
+func (p Pointer) Set(doc, value any) (any, error) {
+	return p.update(doc, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			index := len(node)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(node)+1); err != nil {
+					return nil, err
+				}
+			}
+			// build a new slice so the caller's backing array is left untouched
+			s := make([]any, 0, len(node)+1)
+			s = append(s, node[:index]...)
+			s = append(s, value)
+			return append(s, node[index:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot index %T", parent)
+		}
+	}, value)
+}
+
+// Replace replaces the existing value at the pointer and returns the updated document.
+func (p Pointer) Replace(doc, value any) (any, error) {
+	return p.update(doc, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("key not found")
+			}
+			node[token] = value
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot index %T", parent)
+		}
+	}, value)
+}
+
+// Remove removes the value at the pointer and returns the updated document. Objects are
+// modified in place, and arrays are copied as with Set.
+func (p Pointer) Remove(doc any) (any, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return p.update(doc, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("key not found")
+			}
+			delete(node, token)
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			s := make([]any, 0, len(node)-1)
+			s = append(s, node[:index]...)
+			return append(s, node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot index %T", parent)
+		}
+	}, nil)
+}
+
+// update applies fn to the parent of the pointer's target and stores the result back
+// into the document, since changing the length of an array produces a new slice.
+func (p Pointer) update(doc any, fn func(parent any, token string) (any, error), root any) (any, error) {
+	if len(p) == 0 {
+		return root, nil
+	}
+	parentPtr := p[:len(p)-1]
+	parent, err := parentPtr.Get(doc)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := fn(parent, p[len(p)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	if len(parentPtr) == 0 {
+		return updated, nil
+	}
+	return parentPtr.Replace(doc, updated)
+}
+
+// arrayIndex parses an array index token that must be less than n.
+func arrayIndex(token string, n int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index >= n {
+		return 0, fmt.Errorf("array index %s out of range", token)
+	}
+	return index, nil
+}
@@ -0,0 +1,99 @@
+package json
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// decodeDoc decodes a JSON document for use in tests.
+func decodeDoc(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return v
+}
+
+func TestPointer_Get(t *testing.T) {
+	doc := decodeDoc(t, `{"foo":["bar","baz"],"":0,"a/b":1,"m~n":8,"k\"l":6}`)
+	cases := map[string]any{
+		"":       doc,
+		"/foo/0": "bar",
+		"/":      0.0,
+		"/a~1b":  1.0,
+		"/m~0n":  8.0,
+		"/k\"l":  6.0,
+	}
+	for s, expected := range cases {
+		p, err := ParsePointer(s)
+		if err != nil {
+			t.Fatalf("%q: expected no error, got %v", s, err)
+		}
+		if p.String() != s {
+			t.Fatalf("expected %q to round trip, got %q", s, p.String())
+		}
+		v, err := p.Get(doc)
+		if err != nil || !Equal(v, expected) {
+			t.Fatalf("%q: expected %v, got %v, %v", s, expected, v, err)
+		}
+	}
+	for _, s := range []string{"foo", "/foo/2", "/foo/01", "/foo/-", "/bar", "/a~2b"} {
+		p, err := ParsePointer(s)
+		if err == nil {
+			_, err = p.Get(doc)
+		}
+		if err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+	}
+}
+
+func TestPointer_SetRemove(t *testing.T) {
+	doc := decodeDoc(t, `{"foo":["bar","baz"],"obj":{"a":1}}`)
+	var err error
+	for _, step := range []struct {
+		ptr   string
+		value any
+	}{{"/foo/1", "qux"}, {"/foo/-", "end"}, {"/obj/b", 2.0}} {
+		p, _ := ParsePointer(step.ptr)
+		if doc, err = p.Set(doc, step.value); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	p, _ := ParsePointer("/foo/0")
+	if doc, err = p.Remove(doc); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := decodeDoc(t, `{"foo":["qux","baz","end"],"obj":{"a":1,"b":2}}`)
+	if !Equal(doc, expected) {
+		t.Fatalf("expected %v, got %v", expected, doc)
+	}
+}
+
+func TestPointer_ArraysNotAliased(t *testing.T) {
+	arr := make([]any, 3, 4)
+	copy(arr, []any{1.0, 2.0, 3.0})
+	removed, err := Pointer{"0"}.Remove(arr)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(removed, []any{2.0, 3.0}) || !reflect.DeepEqual(arr, []any{1.0, 2.0, 3.0}) {
+		t.Fatalf("expected original to be unchanged, got %v and %v", removed, arr)
+	}
+	inserted, err := Pointer{"1"}.Set(arr, 9.0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	appended, err := Pointer{"-"}.Set(arr, 4.0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(inserted, []any{1.0, 9.0, 2.0, 3.0}) || !reflect.DeepEqual(appended, []any{1.0, 2.0, 3.0, 4.0}) {
+		t.Fatalf("unexpected results %v and %v", inserted, appended)
+	}
+	if !reflect.DeepEqual(arr[:4], []any{1.0, 2.0, 3.0, nil}) {
+		t.Fatalf("expected original backing array to be unchanged, got %v", arr[:4])
+	}
+}
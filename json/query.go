@@ -0,0 +1,711 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PathSyntaxError describes an invalid JSONPath expression.
+type PathSyntaxError struct {
+	Expr   string
+	Offset int
+	Msg    string
+}
+
+func (e *PathSyntaxError) Error() string {
+	return fmt.Sprintf("invalid path %q at offset %d: %s", e.Expr, e.Offset, e.Msg)
+}
+
+// Path is a compiled JSONPath expression. It supports a practical subset of RFC 9535:
+//
+//	$                 the root
+//	.name, ['name']   a member of an object
+//	.*, [*]           every member or element
+//	..name, ..[0]     recursive descent
+//	[0], [-1]         an element, counting from the end when negative
+//	[start:end:step]  a slice of an array
+//	[0,'a']           a union of selectors
+//	[?@.x > 1]        a filter with ==, !=, <, <=, >, >=, &&, ||, ! and parentheses
+//
+// Filters compare @ or $ queries with each other or with number, string, true, false and
+// null literals, using the first node a query matches. A query on its own tests for existence.
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// segment is a set of selectors applied to each node, or to each node and its descendants.
+type segment struct {
+	recursive bool
+	selectors []selector
+}
+
+// selector selects children of a node.
+type selector interface {
+	selectFrom(root, v any, out []any) []any
+}
+
+type (
+	nameSelector     string
+	wildcardSelector struct{}
+	indexSelector    int
+	sliceSelector    struct {
+		start, end *int
+		step       int
+	}
+	filterSelector struct {
+		expr filterExpr
+	}
+)
+
+// CompilePath parses a JSONPath expression.
+func CompilePath(expr string) (*Path, error) {
+	p := &pathParser{expr: expr}
+	p.skipSpace()
+	if !p.consume("$") {
+		return nil, p.errorf("expected $")
+	}
+	segments, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(expr) {
+		return nil, p.errorf("unexpected %q", expr[p.pos:])
+	}
+	return &Path{expr, segments}, nil
+}
+
+// MustCompilePath is like CompilePath but panics if the expression is invalid.
+func MustCompilePath(expr string) *Path {
+	p, err := CompilePath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source expression.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Select returns the nodes of a decoded JSON document matched by the path, in document
+// order with object members sorted by name. Values that are not already decoded trees are
+// converted first.
+func (p *Path) Select(doc any) []any {
+	doc = tree(doc)
+	return evaluate(doc, doc, p.segments)
+}
+
+// Select compiles expr and returns the nodes of doc it matches.
+func Select(doc any, expr string) ([]any, error) {
+	p, err := CompilePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Select(doc), nil
+}
+
+// Query compiles expr and decodes every node of doc it matches into a T.
+func Query[T any](doc any, expr string) ([]T, error) {
+	nodes, err := Select(doc, expr)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, len(nodes))
+	for i, node := range nodes {
+		b, err := json.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+		if err = decodeValue(b, &out[i], nil); err != nil {
+			return nil, fmt.Errorf("%s: match %d: %w", expr, i, err)
+		}
+	}
+	return out, nil
+}
+
+// tree returns v as a decoded JSON tree, converting it if needed.
+func tree(v any) any {
+	switch v.(type) {
+	case nil, bool, float64, json.Number, string, []any, map[string]any:
+		return v
+	}
+	if out, err := normalize(v); err == nil {
+		return out
+	}
+	return v
+}
+
+// evaluate applies segments to v.
+func evaluate(root, v any, segments []segment) []any {
+	nodes := []any{v}
+	for _, seg := range segments {
+		var next []any
+		for _, node := range nodes {
+			if seg.recursive {
+				for _, d := range descendants(node, nil) {
+					for _, sel := range seg.selectors {
+						next = sel.selectFrom(root, d, next)
+					}
+				}
+				continue
+			}
+			for _, sel := range seg.selectors {
+				next = sel.selectFrom(root, node, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants appends v and every value nested in it, in document order.
+func descendants(v any, out []any) []any {
+	out = append(out, v)
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(v) {
+			out = descendants(v[k], out)
+		}
+	case []any:
+		for _, child := range v {
+			out = descendants(child, out)
+		}
+	}
+	return out
+}
+
+// children returns the members or elements of v.
+func children(v any) []any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make([]any, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			out = append(out, v[k])
+		}
+		return out
+	case []any:
+		return v
+	default:
+		return nil
+	}
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s nameSelector) selectFrom(_, v any, out []any) []any {
+	if m, ok := v.(map[string]any); ok {
+		if child, ok := m[string(s)]; ok {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+func (wildcardSelector) selectFrom(_, v any, out []any) []any {
+	return append(out, children(v)...)
+}
+
+func (s indexSelector) selectFrom(_, v any, out []any) []any {
+	a, ok := v.([]any)
+	if !ok {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(a)
+	}
+	if i >= 0 && i < len(a) {
+		out = append(out, a[i])
+	}
+	return out
+}
+
+func (s sliceSelector) selectFrom(_, v any, out []any) []any {
+	a, ok := v.([]any)
+	if !ok || s.step == 0 {
+		return out
+	}
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		if *p < 0 {
+			return *p + len(a)
+		}
+		return *p
+	}
+	// the number of elements is computed up front, since stepping past the end with a
+	// large step would overflow
+	var start, n int
+	if s.step > 0 {
+		start = max(bound(s.start, 0), 0)
+		if end := min(bound(s.end, len(a)), len(a)); start < end {
+			n = (end-start-1)/s.step + 1
+		}
+	} else {
+		start = min(bound(s.start, len(a)-1), len(a)-1)
+		if end := max(bound(s.end, -len(a)-1), -1); start > end {
+			n = -((start - end - 1) / s.step) + 1
+		}
+	}
+	for i := 0; i < n; i++ {
+		out = append(out, a[start+i*s.step])
+	}
+	return out
+}
+
+func (s filterSelector) selectFrom(root, v any, out []any) []any {
+	for _, child := range children(v) {
+		if s.expr.eval(root, child) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// filterExpr is a boolean expression evaluated against the current node.
+type filterExpr interface {
+	eval(root, cur any) bool
+}
+
+// operand is a value in a comparison. Queries that match nothing are missing.
+type operand interface {
+	value(root, cur any) (any, bool)
+}
+
+type (
+	orExpr      struct{ a, b filterExpr }
+	andExpr     struct{ a, b filterExpr }
+	notExpr     struct{ e filterExpr }
+	existsExpr  struct{ q *queryOperand }
+	compareExpr struct {
+		op   string
+		a, b operand
+	}
+	literalOperand struct{ v any }
+	queryOperand   struct {
+		absolute bool
+		segments []segment
+	}
+)
+
+func (e orExpr) eval(root, cur any) bool  { return e.a.eval(root, cur) || e.b.eval(root, cur) }
+func (e andExpr) eval(root, cur any) bool { return e.a.eval(root, cur) && e.b.eval(root, cur) }
+func (e notExpr) eval(root, cur any) bool { return !e.e.eval(root, cur) }
+
+func (e existsExpr) eval(root, cur any) bool {
+	return len(e.q.nodes(root, cur)) > 0
+}
+
+func (e compareExpr) eval(root, cur any) bool {
+	a, aok := e.a.value(root, cur)
+	b, bok := e.b.value(root, cur)
+	switch e.op {
+	case "==":
+		return aok == bok && (!aok || Equal(a, b))
+	case "!=":
+		return aok != bok || (aok && !Equal(a, b))
+	}
+	if !aok || !bok {
+		return false
+	}
+	var c int
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok {
+			return false
+		}
+		c = compareOrdered(x, y)
+	} else if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		if !ok {
+			return false
+		}
+		c = compareOrdered(x, y)
+	} else {
+		return false
+	}
+	switch e.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// compareOrdered returns -1, 0 or 1 as a is less than, equal to or greater than b.
+func compareOrdered[V float64 | string](a, b V) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (o literalOperand) value(_, _ any) (any, bool) {
+	return o.v, true
+}
+
+// value returns the first node matched by the query.
+func (o *queryOperand) value(root, cur any) (any, bool) {
+	nodes := o.nodes(root, cur)
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+func (o *queryOperand) nodes(root, cur any) []any {
+	if o.absolute {
+		cur = root
+	}
+	return evaluate(root, cur, o.segments)
+}
+
+// pathParser parses a JSONPath expression.
+type pathParser struct {
+	expr string
+	pos  int
+}
+
+func (p *pathParser) errorf(format string, args ...any) error {
+	return &PathSyntaxError{p.expr, p.pos, fmt.Sprintf(format, args...)}
+}
+
+func (p *pathParser) skipSpace() {
+	for p.pos < len(p.expr) && strings.IndexByte(" \t\n\r", p.expr[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// consume advances past s if the input continues with it.
+func (p *pathParser) consume(s string) bool {
+	if strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// peek returns the next byte, or 0 at the end of the input.
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+// segments parses segments until the input no longer continues with one.
+func (p *pathParser) segments() ([]segment, error) {
+	var segments []segment
+	for {
+		start := p.pos
+		p.skipSpace()
+		var seg segment
+		switch {
+		case p.consume(".."):
+			seg.recursive = true
+			if p.peek() == '[' {
+				break
+			}
+			fallthrough
+		case p.consume("."):
+			sel, err := p.dotSelector()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = []selector{sel}
+			segments = append(segments, seg)
+			continue
+		case p.peek() == '[':
+		default:
+			p.pos = start
+			return segments, nil
+		}
+		sels, err := p.bracket()
+		if err != nil {
+			return nil, err
+		}
+		seg.selectors = sels
+		segments = append(segments, seg)
+	}
+}
+
+// dotSelector parses the name or wildcard following a dot.
+func (p *pathParser) dotSelector() (selector, error) {
+	if p.consume("*") {
+		return wildcardSelector{}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		if c >= utf8.RuneSelf || c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected member name")
+	}
+	return nameSelector(p.expr[start:p.pos]), nil
+}
+
+// bracket parses a bracketed, comma separated list of selectors.
+func (p *pathParser) bracket() ([]selector, error) {
+	p.pos++
+	var sels []selector
+	for {
+		p.skipSpace()
+		sel, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipSpace()
+		if p.consume("]") {
+			return sels, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+// selector parses a single selector inside brackets.
+func (p *pathParser) selector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '\'' || c == '"':
+		s, err := p.str()
+		return nameSelector(s), err
+	case c == '?':
+		p.pos++
+		expr, err := p.or()
+		return filterSelector{expr}, err
+	default:
+		return p.indexOrSlice()
+	}
+}
+
+// indexOrSlice parses an index or a start:end:step slice.
+func (p *pathParser) indexOrSlice() (selector, error) {
+	var parts [3]*int
+	n := 0
+	for {
+		p.skipSpace()
+		if i, ok, err := p.integer(); err != nil {
+			return nil, err
+		} else if ok {
+			parts[n] = &i
+		}
+		p.skipSpace()
+		if n == 2 || !p.consume(":") {
+			break
+		}
+		n++
+	}
+	if n == 0 {
+		if parts[0] == nil {
+			return nil, p.errorf("expected selector")
+		}
+		return indexSelector(*parts[0]), nil
+	}
+	step := 1
+	if parts[2] != nil {
+		step = *parts[2]
+	}
+	return sliceSelector{parts[0], parts[1], step}, nil
+}
+
+// integer parses an optional signed integer.
+func (p *pathParser) integer() (int, bool, error) {
+	start := p.pos
+	p.consume("-")
+	for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	i, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false, p.errorf("invalid integer")
+	}
+	return i, true, nil
+}
+
+// str parses a single or double quoted string with JSON escapes.
+func (p *pathParser) str() (string, error) {
+	quote := p.expr[p.pos]
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.expr):
+			p.pos++
+			switch e := p.expr[p.pos]; e {
+			case '\'', '"', '\\', '/':
+				b.WriteByte(e)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				var r string
+				if err := json.Unmarshal([]byte(`"\`+p.expr[p.pos:min(p.pos+5, len(p.expr))]+`"`), &r); err != nil {
+					return "", p.errorf("invalid escape")
+				}
+				b.WriteString(r)
+				p.pos += 4
+			default:
+				return "", p.errorf("invalid escape")
+			}
+			p.pos++
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+// or parses a || separated list of && expressions.
+func (p *pathParser) or() (filterExpr, error) {
+	expr, err := p.and()
+	for err == nil {
+		p.skipSpace()
+		if !p.consume("||") {
+			break
+		}
+		var rhs filterExpr
+		if rhs, err = p.and(); err == nil {
+			expr = orExpr{expr, rhs}
+		}
+	}
+	return expr, err
+}
+
+// and parses a && separated list of unary expressions.
+func (p *pathParser) and() (filterExpr, error) {
+	expr, err := p.unary()
+	for err == nil {
+		p.skipSpace()
+		if !p.consume("&&") {
+			break
+		}
+		var rhs filterExpr
+		if rhs, err = p.unary(); err == nil {
+			expr = andExpr{expr, rhs}
+		}
+	}
+	return expr, err
+}
+
+// unary parses a negation, a parenthesized expression, a comparison or an existence test.
+func (p *pathParser) unary() (filterExpr, error) {
+	p.skipSpace()
+	if p.peek() == '!' && !strings.HasPrefix(p.expr[p.pos:], "!=") {
+		p.pos++
+		e, err := p.unary()
+		return notExpr{e}, err
+	}
+	if p.consume("(") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return e, nil
+	}
+	a, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			p.skipSpace()
+			b, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{op, a, b}, nil
+		}
+	}
+	q, ok := a.(*queryOperand)
+	if !ok {
+		return nil, p.errorf("expected comparison")
+	}
+	return existsExpr{q}, nil
+}
+
+// operand parses a query or a literal.
+func (p *pathParser) operand() (operand, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.segments()
+		return &queryOperand{c == '$', segments}, err
+	case c == '\'' || c == '"':
+		s, err := p.str()
+		return literalOperand{s}, err
+	case c == '-' || c >= '0' && c <= '9':
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.expr) && strings.IndexByte("0123456789.eE+-", p.expr[p.pos]) >= 0 {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.expr[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number")
+		}
+		return literalOperand{f}, nil
+	}
+	for word, v := range map[string]any{"true": true, "false": false, "null": nil} {
+		if p.consume(word) {
+			return literalOperand{v}, nil
+		}
+	}
+	return nil, p.errorf("expected operand")
+}
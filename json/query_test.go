@@ -0,0 +1,105 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testQueryDoc = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399},
+		"limit": 10
+	}
+}`
+
+func TestSelect(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(testQueryDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want []any
+	}{
+		{`$.store.book[*].author`, []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{`$..author`, []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{`$.store.*.color`, []any{"red"}},
+		{`$['store']["bicycle"].price`, []any{399.0}},
+		{`$..book[2].title`, []any{"Moby Dick"}},
+		{`$..book[-1].title`, []any{"The Lord of the Rings"}},
+		{`$..book[:2].price`, []any{8.95, 12.99}},
+		{`$..book[1:].price`, []any{12.99, 8.99, 22.99}},
+		{`$..book[::-2].price`, []any{22.99, 12.99}},
+		{`$..book[0,3].price`, []any{8.95, 22.99}},
+		{`$..book[?@.isbn].title`, []any{"Moby Dick", "The Lord of the Rings"}},
+		{`$..book[?(@.price < 10)].title`, []any{"Sayings of the Century", "Moby Dick"}},
+		{`$..book[?@.price > $.store.limit].price`, []any{12.99, 22.99}},
+		{`$..book[?@.category == 'fiction' && @.price <= 9].author`, []any{"Herman Melville"}},
+		{`$..book[?@.author == "Nigel Rees" || @.price >= 20].price`, []any{8.95, 22.99}},
+		{`$..book[?!@.isbn && @.category != "reference"].title`, []any{"Sword of Honour"}},
+		{`$..book[?@.title > "S"].price`, []any{8.95, 12.99, 22.99}},
+		{`$.store.missing`, nil},
+		{`$..*[?@ == 399]`, []any{399.0}},
+		{`$..book[1::9223372036854775807].price`, []any{12.99}},
+		{`$..book[2::-9223372036854775808].price`, []any{8.99}},
+		{`$..book[-1:-9223372036854775808:-2].price`, []any{22.99, 12.99}},
+		{`$..book[9223372036854775807:].price`, nil},
+	}
+	for _, tt := range tests {
+		got, err := Select(doc, tt.expr)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+	got, err := Select([]any{1.0, 2.0, 3.0}, `$[1::9223372036854775807]`)
+	if err != nil || !reflect.DeepEqual(got, []any{2.0}) {
+		t.Errorf("expected [2] for a huge step, got %v, %v", got, err)
+	}
+}
+
+func TestCompilePathErrors(t *testing.T) {
+	for _, expr := range []string{``, `store`, `$.`, `$[`, `$['a'`, `$[?@.a ==]`, `$[?(@.a]`, `$[1:x]`, `$[?1]`, `$.a b`} {
+		_, err := CompilePath(expr)
+		var serr *PathSyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("%q: expected PathSyntaxError, got %v", expr, err)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	type book struct {
+		Author string  `json:"author"`
+		Price  float64 `json:"price"`
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(testQueryDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+	books, err := Query[book](doc, `$.store.book[?@.price > 20]`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []book{{"J. R. R. Tolkien", 22.99}}; !reflect.DeepEqual(books, want) {
+		t.Fatalf("expected %v, got %v", want, books)
+	}
+	fields, err := Query[string](testStruct{Field1: "a", Field2: 2}, `$.field1`)
+	if err != nil || !reflect.DeepEqual(fields, []string{"a"}) {
+		t.Fatalf("expected [a], got %v, %v", fields, err)
+	}
+	if _, err = Query[int](doc, `$..author`); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
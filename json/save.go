@@ -0,0 +1,139 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SaveOpts defines the options for saving a json file.
+type SaveOpts struct {
+	// Atomic writes to a temporary file in the same directory, syncs it and renames it over
+	// the target, so a crash never leaves a partially written file.
+	Atomic bool
+	// Backup keeps the previous version of the file as filename.bak. Requires Atomic.
+	Backup bool
+	// Perm is the mode used when the file does not already exist. Defaults to 0644, or to
+	// 0666 before the umask, as with SaveFile, when not Atomic.
+	Perm fs.FileMode
+	// ForcePerm applies Perm even when the file already exists, instead of keeping its mode.
+	ForcePerm bool
+}
+
+// SaveFileWithOpts saves a struct to a json file with the specified options.
+func SaveFileWithOpts(filename string, s any, opts *SaveOpts) error {
+	if opts == nil {
+		return SaveFile(filename, s)
+	}
+	if !opts.Atomic {
+		if opts.Backup {
+			return errors.New("backup requires an atomic save")
+		}
+		if opts.Perm != 0 {
+			return saveFile(filename, s, opts.Perm, opts.ForcePerm)
+		}
+		return SaveFile(filename, s)
+	}
+	return writeAtomic(filepath.Clean(filename), opts, func(w io.Writer) error {
+		return encode(w, filename, s)
+	})
+}
+
+// encode writes s to w, compressing it if the extension of filename has a registered codec.
+func encode(w io.Writer, filename string, s any) error {
+	c := codecForName(filename)
+	if c == nil {
+		return json.NewEncoder(w).Encode(s)
+	}
+	cw, err := c.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if err = json.NewEncoder(cw).Encode(s); err != nil {
+		_ = cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+// writeAtomic replaces filename with the output of write via a synced temporary file.
+func writeAtomic(filename string, opts *SaveOpts, write func(io.Writer) error) error {
+	perm := opts.Perm
+	if perm == 0 {
+		perm = 0o644
+	}
+	info, err := os.Stat(filename)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if exists && !opts.ForcePerm {
+		perm = info.Mode().Perm()
+	}
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if exists && opts.Backup {
+		if err = backup(filename); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// backup links or copies filename to filename.bak, replacing any previous backup.
+func backup(filename string) error {
+	bak := filename + ".bak"
+	if err := os.Remove(bak); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if os.Link(filename, bak) == nil {
+		return nil
+	}
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
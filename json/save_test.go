@@ -0,0 +1,99 @@
+package json
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveFileWithOpts_Atomic(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(filename, []byte(`{"field1":"old","field2":1}`), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ts := &testStruct{Field1: "new", Field2: 2}
+	if err := SaveFileWithOpts(filename, ts, &SaveOpts{Atomic: true, Backup: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	result, err := LoadFile[testStruct](filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *result != *ts {
+		t.Fatalf("expected %v, got %v", ts, result)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600 to be preserved, got %v", info.Mode().Perm())
+	}
+	bak, err := LoadFile[testStruct](filename + ".bak")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bak.Field1 != "old" {
+		t.Fatalf("expected backup of previous version, got %v", bak)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("expected only the file and its backup, got %d entries", len(entries))
+	}
+}
+
+func TestSaveFileWithOpts_AtomicNew(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	ts := &testStruct{Field1: "test", Field2: 123}
+	if err := SaveFileWithOpts(filename, ts, &SaveOpts{Atomic: true, Backup: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Fatalf("expected default mode 0644, got %v", info.Mode().Perm())
+	}
+	if _, err = os.Stat(filename + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup for a new file, got %v", err)
+	}
+}
+
+func TestSaveFileWithOpts_NonAtomic(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	ts := &testStruct{Field1: "test", Field2: 123}
+	if err := SaveFileWithOpts(filename, ts, &SaveOpts{Perm: 0o600}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	result, err := LoadFile[testStruct](filename)
+	if err != nil || *result != *ts {
+		t.Fatalf("expected %v, got %v, %v", ts, result, err)
+	}
+	if err = SaveFileWithOpts(filename, ts, &SaveOpts{Backup: true}); err == nil {
+		t.Fatal("expected error for backup without atomic")
+	}
+}
+
+func TestSaveFileWithOpts_ForcePerm(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		filename := filepath.Join(t.TempDir(), "state.json")
+		if err := os.WriteFile(filename, []byte(`{}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveFileWithOpts(filename, &testStruct{}, &SaveOpts{Atomic: atomic, Perm: 0o600, ForcePerm: true}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if info, _ := os.Stat(filename); info.Mode().Perm() != 0o600 {
+			t.Fatalf("atomic=%v: expected mode 0600, got %v", atomic, info.Mode().Perm())
+		}
+	}
+}
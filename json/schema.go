@@ -0,0 +1,561 @@
+package json
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaDraft is the JSON Schema dialect produced by GenerateSchema.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        Types              `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Default     any                `json:"default,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+}
+
+// Types is the type keyword of a schema, written as a string when it has a single type.
+type Types []string
+
+// MarshalJSON implements json.Marshaler.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*t = Types{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting the boolean schemas true and false.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if json.Unmarshal(data, &b) == nil {
+		*s = Schema{}
+		if !b {
+			s.Not = &Schema{}
+		}
+		return nil
+	}
+	type schema Schema
+	return json.Unmarshal(data, (*schema)(s))
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// generator builds a schema, collecting named struct types into $defs.
+type generator struct {
+	root  reflect.Type
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+// GenerateSchema reflects over T to produce a JSON Schema draft 2020-12 document. It honors
+// json tags and embedded structs, and the default and validate tags used by SetDefaults and
+// Validate. Only fields tagged validate:"required" are required, so the schema accepts the
+// partial files LoadFile does.
+func GenerateSchema[T any]() *Schema {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	g := &generator{root: t, defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+	s := g.schema(t)
+	// a root struct refers to itself; types with their own encoding such as time.Time do not
+	if s.Ref == "#" {
+		s = g.structSchema(t)
+	}
+	s.Schema = SchemaDraft
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+// schema returns the schema for a type.
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t == durationType:
+		return &Schema{Type: Types{"integer"}}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: Types{"string"}}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{"integer"}, Minimum: ptr(0.0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, Format: "byte"}
+		}
+		return &Schema{Type: Types{"array", "null"}, Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: g.schema(t.Elem()), MinItems: ptr(t.Len()), MaxItems: ptr(t.Len())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		switch {
+		case s.Ref != "":
+			return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+		case len(s.Type) > 0 && !contains(s.Type, "null"):
+			s.Type = append(s.Type, "null")
+		}
+		return s
+	case reflect.Struct:
+		return g.ref(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// ref returns a reference to the definition of a struct type, creating it if needed.
+func (g *generator) ref(t reflect.Type) *Schema {
+	if t == g.root {
+		return &Schema{Ref: "#"}
+	}
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; g.defs[name] != nil; i++ {
+			name = t.Name() + strconv.Itoa(i)
+		}
+		g.names[t] = name
+		g.defs[name] = &Schema{}
+		*g.defs[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// structSchema returns the schema for the fields of a struct.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	g.fields(t, s)
+	sort.Strings(s.Required)
+	return s
+}
+
+// fields adds the properties of a struct, flattening embedded structs.
+func (g *generator) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		ft := field.Type
+		if field.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		var prop *Schema
+		if hasOption(opts, "string") {
+			prop = &Schema{Type: Types{"string"}}
+		} else {
+			prop = g.schema(ft)
+		}
+		def, hasDefault := field.Tag.Lookup("default")
+		if hasDefault {
+			prop.Default = defaultValue(ft, def)
+		}
+		validate := field.Tag.Get("validate")
+		applyRules(prop, ft, validate)
+		if hasRule(validate, "required") {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// applyRules translates validate tag rules into schema keywords.
+func applyRules(s *Schema, t reflect.Type, validate string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, rule := range strings.Split(validate, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			isMin := name == "min"
+			switch t.Kind() {
+			case reflect.String:
+				setBound(&s.MinLength, &s.MaxLength, isMin, int(n))
+			case reflect.Slice, reflect.Array:
+				setBound(&s.MinItems, &s.MaxItems, isMin, int(n))
+			case reflect.Map:
+				setBound(&s.MinProperties, &s.MaxProperties, isMin, int(n))
+			default:
+				setBound(&s.Minimum, &s.Maximum, isMin, n)
+			}
+		case "oneof":
+			for _, option := range strings.Split(arg, "|") {
+				s.Enum = append(s.Enum, defaultValue(t, option))
+			}
+		case "required":
+			switch t.Kind() {
+			case reflect.String:
+				s.MinLength = ptr(1)
+			case reflect.Slice, reflect.Map:
+				s.Type = without(s.Type, "null")
+			}
+		}
+	}
+}
+
+// setBound sets the lower or upper bound.
+func setBound[N int | float64](min, max **N, isMin bool, n N) {
+	if isMin {
+		*min = &n
+	} else {
+		*max = &n
+	}
+}
+
+// defaultValue converts a tag value to the JSON value it represents for type t.
+func defaultValue(t reflect.Type, s string) any {
+	v := reflect.New(t).Elem()
+	if err := setDefault(v, s); err != nil {
+		return s
+	}
+	return v.Interface()
+}
+
+// hasOption reports whether a comma-separated list of json tag options contains option.
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRule reports whether a validate tag contains rule.
+func hasRule(validate, rule string) bool {
+	for _, r := range strings.Split(validate, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether types contains t.
+func contains(types Types, t string) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns types without t.
+func without(types Types, t string) Types {
+	out := make(Types, 0, len(types))
+	for _, x := range types {
+		if x != t {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// ptr returns a pointer to v.
+func ptr[V any](v V) *V {
+	return &v
+}
+
+// SchemaError describes a value that does not match a schema.
+type SchemaError struct {
+	// Path is the JSON Pointer of the invalid value.
+	Path string
+	Msg  string
+}
+
+func (e *SchemaError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + e.Msg
+}
+
+// Validate checks a decoded JSON value against the schema and returns a *SchemaError for
+// every violation joined together. It supports $ref within the document, type, enum,
+// numeric and length bounds, pattern, items, properties, required, additionalProperties,
+// allOf, anyOf and not.
+func (s *Schema) Validate(v any) error {
+	tree, err := normalize(v)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	s.validate(s, tree, "", []*Schema{s}, &errs)
+	return errors.Join(errs...)
+}
+
+// ValidateFile checks a json file against the schema.
+func (s *Schema) ValidateFile(filename string) error {
+	doc, err := LoadFileWithOpts[any](filename, &LoadOpts{UseNumber: true})
+	if err != nil {
+		return err
+	}
+	if err = s.Validate(*doc); err != nil {
+		return &FileError{File: filename, Err: err}
+	}
+	return nil
+}
+
+// validate appends the violations of v at path. refs holds the schemas already applied at
+// path through $ref, so a reference cycle is reported instead of recursing forever.
+func (s *Schema) validate(root *Schema, v any, path string, refs []*Schema, errs *[]error) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, &SchemaError{path, fmt.Sprintf(format, args...)})
+	}
+	if s.Ref != "" {
+		target, err := root.resolve(s.Ref)
+		if err != nil {
+			fail("%v", err)
+			return
+		}
+		if slices.Contains(refs, target) {
+			fail("circular $ref %q", s.Ref)
+			return
+		}
+		target.validate(root, v, path, append(refs, target), errs)
+	}
+	if len(s.Type) > 0 && !matchesType(s.Type, v) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), typeName(v))
+		return
+	}
+	if len(s.Enum) > 0 && !matchesEnum(s.Enum, v) {
+		fail("must be one of %s", render(s.Enum))
+	}
+	for _, sub := range s.AllOf {
+		sub.validate(root, v, path, refs, errs)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			var subErrs []error
+			sub.validate(root, v, path, refs, &subErrs)
+			if len(subErrs) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("does not match any allowed schema")
+		}
+	}
+	if s.Not != nil {
+		var subErrs []error
+		s.Not.validate(root, v, path, refs, &subErrs)
+		if len(subErrs) == 0 {
+			fail("is not allowed")
+		}
+	}
+	switch v := v.(type) {
+	case json.Number, float64:
+		n, _ := number(v)
+		if s.Minimum != nil && n < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("must have length at least %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must have length at most %d", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				fail("invalid pattern %q: %v", s.Pattern, err)
+			} else if !re.MatchString(v) {
+				fail("must match pattern %q", s.Pattern)
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, item, path+"/"+strconv.Itoa(i), nil, errs)
+			}
+		}
+	case map[string]any:
+		if s.MinProperties != nil && len(v) < *s.MinProperties {
+			fail("must have at least %d properties", *s.MinProperties)
+		}
+		if s.MaxProperties != nil && len(v) > *s.MaxProperties {
+			fail("must have at most %d properties", *s.MaxProperties)
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + escapeToken(k)
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(root, v[k], p, nil, errs)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(root, v[k], p, nil, errs)
+			}
+		}
+	}
+}
+
+// resolve finds the subschema a $ref points to within the root document.
+func (s *Schema) resolve(ref string) (*Schema, error) {
+	if ref == "#" {
+		return s, nil
+	}
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if ok {
+		if def, ok := s.Defs[name]; ok {
+			return def, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot resolve $ref %q", ref)
+}
+
+// matchesType reports whether v is one of the JSON Schema types.
+func matchesType(types Types, v any) bool {
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if n, ok := number(v); ok && n == math.Trunc(n) {
+				return true
+			}
+		case typeName(v):
+			return true
+		}
+	}
+	return false
+}
+
+// matchesEnum reports whether v equals one of the enum values.
+func matchesEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if x, err := normalize(e); err == nil && Equal(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type of a decoded value.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
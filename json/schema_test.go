@@ -0,0 +1,220 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testNode struct {
+	Name     string     `json:"name"`
+	Children []testNode `json:"children,omitempty"`
+}
+
+type testSchemaBase struct {
+	ID string `json:"id"`
+}
+
+type testSchemaConfig struct {
+	testSchemaBase
+	Note   string     `json:"note,omitempty"`
+	Hidden string     `json:"-"`
+	Server *testNode  `json:"server"`
+	Count  uint       `json:"count,string"`
+	Nodes  []testNode `json:"nodes,omitempty"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	s := GenerateSchema[testValidConfig]()
+	if s.Schema != SchemaDraft || !reflect.DeepEqual(s.Type, Types{"object"}) {
+		t.Fatalf("unexpected root %+v", s)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"servers"}) {
+		t.Fatalf("unexpected required %v", s.Required)
+	}
+	mode := s.Properties["mode"]
+	if mode.Default != "dev" || !reflect.DeepEqual(mode.Enum, []any{"dev", "prod"}) {
+		t.Fatalf("unexpected mode schema %+v", mode)
+	}
+	if s.Properties["timeout"].Default == nil || s.Properties["tags"].MaxItems == nil || *s.Properties["tags"].MaxItems != 3 {
+		t.Fatalf("unexpected timeout or tags schema")
+	}
+	if !reflect.DeepEqual(s.Properties["servers"].Type, Types{"array"}) {
+		t.Fatalf("expected required slice not to be nullable, got %v", s.Properties["servers"].Type)
+	}
+	if s.Properties["servers"].Items.Ref != "#/$defs/testValidServer" {
+		t.Fatalf("expected $ref to server definition, got %+v", s.Properties["servers"].Items)
+	}
+	server := s.Defs["testValidServer"]
+	if server == nil || *server.Properties["port"].Minimum != 1 || *server.Properties["port"].Maximum != 65535 {
+		t.Fatalf("unexpected server definition %+v", server)
+	}
+	if *server.Properties["host"].MinLength != 1 || !reflect.DeepEqual(server.Required, []string{"host"}) {
+		t.Fatalf("unexpected server host %+v", server)
+	}
+	if primary := s.Properties["primary"]; len(primary.AnyOf) != 2 || primary.AnyOf[0].Ref != "#/$defs/testValidServer" {
+		t.Fatalf("unexpected primary %+v", s.Properties["primary"])
+	}
+}
+
+func TestGenerateSchemaRootTypes(t *testing.T) {
+	s := GenerateSchema[time.Time]()
+	if !reflect.DeepEqual(s.Type, Types{"string"}) || s.Format != "date-time" || s.Properties != nil {
+		t.Fatalf("expected date-time string schema, got %+v", s)
+	}
+	if s = GenerateSchema[json.RawMessage](); s.Type != nil || s.Schema != SchemaDraft {
+		t.Fatalf("expected unconstrained schema, got %+v", s)
+	}
+}
+
+func TestGenerateSchemaEmbeddedAndRecursive(t *testing.T) {
+	s := GenerateSchema[testSchemaConfig]()
+	if _, ok := s.Properties["id"]; !ok {
+		t.Fatalf("expected embedded field to be flattened, got %v", s.Properties)
+	}
+	if _, ok := s.Properties["Hidden"]; ok {
+		t.Fatal("expected skipped field to be omitted")
+	}
+	if len(s.Required) != 0 {
+		t.Fatalf("unexpected required %v", s.Required)
+	}
+	if !reflect.DeepEqual(s.Properties["count"].Type, Types{"string"}) {
+		t.Fatalf("expected string option to produce string, got %v", s.Properties["count"].Type)
+	}
+	node := s.Defs["testNode"]
+	if node == nil || node.Properties["children"].Items.Ref != "#/$defs/testNode" {
+		t.Fatalf("expected recursive definition, got %+v", node)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var decoded Schema
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Required, s.Required) || decoded.Defs["testNode"] == nil {
+		t.Fatalf("expected schema to round trip, got %s", data)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := GenerateSchema[testValidConfig]()
+	var doc any
+	for _, valid := range []string{
+		`{"mode": "prod", "servers": [{"host": "a", "port": 80}], "named": null, "primary": null}`,
+		`{"servers": [{"host": "a"}]}`,
+	} {
+		if err := json.Unmarshal([]byte(valid), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Validate(doc); err != nil {
+			t.Fatalf("%s: expected no error, got %v", valid, err)
+		}
+	}
+	invalid := `{"mode": "test", "tags": ["a", "b", "c", "d"], "servers": [{"host": "", "port": 70000}], "named": {"x": {"port": 1.5}}}`
+	if err := json.Unmarshal([]byte(invalid), &doc); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Validate(doc)
+	for _, want := range []string{
+		`/mode: must be one of`,
+		`/tags: must have at most 3 items`,
+		`/servers/0/host: must have length at least 1`,
+		`/servers/0/port: must be at most 65535`,
+		`/named/x: missing required property "host"`,
+		`/named/x/port: expected integer, got number`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	var serr *SchemaError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected *SchemaError, got %T", err)
+	}
+}
+
+func TestSchemaKeywords(t *testing.T) {
+	var s Schema
+	data := `{
+		"type": "object",
+		"properties": {
+			"code": {"type": "string", "pattern": "^[A-Z]{3}$"},
+			"any": true,
+			"never": false,
+			"n": {"anyOf": [{"type": "string"}, {"type": "integer", "minimum": 0}]}
+		},
+		"additionalProperties": {"type": "boolean"}
+	}`
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	tests := []struct {
+		doc string
+		err string
+	}{
+		{`{"code": "ABC", "any": [1], "n": 3, "extra": true}`, ""},
+		{`{"code": "abc"}`, `/code: must match pattern`},
+		{`{"never": 1}`, `/never: is not allowed`},
+		{`{"n": -1}`, `/n: does not match any allowed schema`},
+		{`{"extra": "x"}`, `/extra: expected boolean, got string`},
+		{`[]`, `/: expected object, got array`},
+	}
+	for _, tt := range tests {
+		var doc any
+		if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+			t.Fatal(err)
+		}
+		err := s.Validate(doc)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", tt.doc, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected %q, got %v", tt.doc, tt.err, err)
+		}
+	}
+}
+
+func TestSchemaRefCycle(t *testing.T) {
+	for _, data := range []string{
+		`{"$ref": "#"}`,
+		`{"$ref": "#/$defs/a", "$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"allOf": [{"$ref": "#/$defs/a"}]}}}`,
+	} {
+		var s Schema
+		if err := json.Unmarshal([]byte(data), &s); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Validate(map[string]any{}); err == nil || !strings.Contains(err.Error(), "circular $ref") {
+			t.Errorf("%s: expected circular $ref error, got %v", data, err)
+		}
+	}
+	var s Schema
+	if err := json.Unmarshal([]byte(`{"type": "object", "properties": {"child": {"$ref": "#"}}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(map[string]any{"child": map[string]any{"child": map[string]any{}}}); err != nil {
+		t.Fatalf("expected recursive schema to validate, got %v", err)
+	}
+}
+
+func TestSchemaValidateFile(t *testing.T) {
+	s := GenerateSchema[testValidServer]()
+	good := writeTemp(t, "good.json", `{"host": "a", "port": 80}`)
+	if err := s.ValidateFile(good); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	bad := writeTemp(t, "bad.json", `{"port": 0}`)
+	err := s.ValidateFile(bad)
+	var ferr *FileError
+	if !errors.As(err, &ferr) || ferr.File != bad {
+		t.Fatalf("expected *FileError for %s, got %v", bad, err)
+	}
+	if !strings.Contains(err.Error(), `missing required property "host"`) || !strings.Contains(err.Error(), "/port: must be at least 1") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
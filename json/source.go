@@ -0,0 +1,90 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// LoadReader loads json from a reader into a new struct.
+func LoadReader[T any](r io.Reader, opts *LoadOpts) (*T, error) {
+	var s T
+	if err := MergeReader(r, &s, opts); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MergeReader loads json from a reader into an existing struct.
+func MergeReader(r io.Reader, s any, opts *LoadOpts) error {
+	data, err := read(r, "", opts)
+	if err != nil {
+		return err
+	}
+	return load("", data, s, opts)
+}
+
+// LoadBytes loads json from a byte slice into a new struct.
+func LoadBytes[T any](data []byte, opts *LoadOpts) (*T, error) {
+	return LoadReader[T](bytes.NewReader(data), opts)
+}
+
+// MergeBytes loads json from a byte slice into an existing struct.
+func MergeBytes(data []byte, s any, opts *LoadOpts) error {
+	return MergeReader(bytes.NewReader(data), s, opts)
+}
+
+// LoadFS loads a json file from a file system, such as an embed.FS, into a new struct.
+func LoadFS[T any](fsys fs.FS, name string, opts *LoadOpts) (*T, error) {
+	var s T
+	if err := MergeFS(fsys, name, &s, opts); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MergeFS loads a json file from a file system into an existing struct.
+func MergeFS(fsys fs.FS, name string, s any, opts *LoadOpts) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	data, err := read(file, name, opts)
+	if err != nil {
+		return err
+	}
+	return load(name, data, s, opts)
+}
+
+// LoadDefault loads a default json file from a file system, such as an embed.FS, and
+// merges the override file from disk over it if it exists. override may be empty. With
+// LoadOpts.Validate, defaults and validation apply once to the merged value.
+func LoadDefault[T any](fsys fs.FS, name, override string, opts *LoadOpts) (*T, error) {
+	var o LoadOpts
+	if opts != nil {
+		o = *opts
+	}
+	validate := o.Validate
+	o.Validate = false
+	s, err := LoadFS[T](fsys, name, &o)
+	if err != nil {
+		return nil, err
+	}
+	if override != "" {
+		err = MergeFileWithOpts(override, s, &o)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if validate {
+		if err = SetDefaults(s); err != nil {
+			return nil, err
+		}
+		if err = Validate(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,78 @@
+package json
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadReader(t *testing.T) {
+	result, err := LoadReader[testStruct](strings.NewReader(`{"field1":"test","field2":123}`), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field1 != "test" || result.Field2 != 123 {
+		t.Fatalf("expected {test 123}, got %v", result)
+	}
+	_, err = LoadBytes[testStruct]([]byte(`{"field1": 1}`), nil)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != "field1" || fileErr.File != "" {
+		t.Fatalf("expected located error without a file name, got %v", err)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults/config.json": {Data: []byte(`{"field1":"default","field2":1}`)},
+		"bad.json":             {Data: []byte(`{"field1":}`)},
+	}
+	result, err := LoadFS[testStruct](fsys, "defaults/config.json", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field1 != "default" {
+		t.Fatalf("expected default, got %v", result.Field1)
+	}
+	_, err = LoadFS[testStruct](fsys, "bad.json", nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "bad.json:1:11: ") {
+		t.Fatalf("expected error naming the file, got %v", err)
+	}
+}
+
+func TestLoadDefault(t *testing.T) {
+	fsys := fstest.MapFS{"config.json": {Data: []byte(`{"field1":"default","field2":1}`)}}
+	override := writeTemp(t, "override.json", `{"field2":2}`)
+	result, err := LoadDefault[testStruct](fsys, "config.json", override, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field1 != "default" || result.Field2 != 2 {
+		t.Fatalf("expected {default 2}, got %v", result)
+	}
+	result, err = LoadDefault[testStruct](fsys, "config.json", filepath.Join(t.TempDir(), "missing.json"), nil)
+	if err != nil {
+		t.Fatalf("expected missing override to be ignored, got %v", err)
+	}
+	if result.Field2 != 1 {
+		t.Fatalf("expected default value, got %v", result.Field2)
+	}
+}
+
+func TestLoadDefault_ValidatesMerged(t *testing.T) {
+	fsys := fstest.MapFS{"server.json": {Data: []byte(`{}`)}}
+	override := writeTemp(t, "override.json", `{"host":"a"}`)
+	result, err := LoadDefault[testValidServer](fsys, "server.json", override, &LoadOpts{Validate: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Host != "a" || result.Port != 8080 {
+		t.Fatalf("expected {a 8080}, got %+v", result)
+	}
+	var verr *ValidationError
+	_, err = LoadDefault[testValidServer](fsys, "server.json", "", &LoadOpts{Validate: true})
+	if !errors.As(err, &verr) || verr.Path != "host" {
+		t.Fatalf("expected host to be required without an override, got %v", err)
+	}
+}
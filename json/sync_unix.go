@@ -0,0 +1,18 @@
+//go:build !windows
+
+package json
+
+import "os"
+
+// syncDir flushes a directory entry change, such as a rename, to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
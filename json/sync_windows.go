@@ -0,0 +1,8 @@
+//go:build windows
+
+package json
+
+// syncDir is a no-op on Windows, where directories cannot be synced.
+func syncDir(string) error {
+	return nil
+}
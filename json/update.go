@@ -0,0 +1,31 @@
+package json
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+)
+
+// Update loads a json file, applies fn to it and saves the result atomically while holding
+// an exclusive lock, so concurrent read-modify-write cycles cannot clobber each other. A
+// missing file starts from the zero value, and nothing is saved if fn returns an error.
+// On Linux the lock is an advisory flock on filename.lock, shared with other processes;
+// elsewhere it only excludes other callers in the same process.
+func Update[T any](filename string, fn func(*T) error) error {
+	filename = filepath.Clean(filename)
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	s, err := LoadFile[T](filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		s = new(T)
+	} else if err != nil {
+		return err
+	}
+	if err = fn(s); err != nil {
+		return err
+	}
+	return SaveFileWithOpts(filename, s, &SaveOpts{Atomic: true})
+}
@@ -0,0 +1,50 @@
+package json
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(filename, func(s *testStruct) error {
+				s.Field2++
+				return nil
+			})
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	result, err := LoadFile[testStruct](filename)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Field2 != 20 {
+		t.Fatalf("expected 20 updates, got %d", result.Field2)
+	}
+}
+
+func TestUpdate_Error(t *testing.T) {
+	filename := writeTemp(t, "state.json", `{"field1":"test","field2":1}`)
+	failed := errors.New("failed")
+	err := Update(filename, func(s *testStruct) error {
+		s.Field2 = 2
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected error from fn, got %v", err)
+	}
+	result, _ := LoadFile[testStruct](filename)
+	if result.Field2 != 1 {
+		t.Fatalf("expected file to be unchanged, got %v", result)
+	}
+}
@@ -0,0 +1,204 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes a value that violates a rule of its validate tag.
+type ValidationError struct {
+	Path string
+	Rule string
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Msg
+	}
+	return e.Path + ": " + e.Msg
+}
+
+// durationType is handled specially so defaults can be written as "5s".
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// SetDefaults sets zero-valued fields to the value of their `default` tag, walking nested
+// structs, pointers, slices, arrays and maps. Strings are used as is, durations are parsed
+// with time.ParseDuration and other values are decoded as JSON, e.g. default:"[\"a\"]".
+// A field explicitly set to its zero value cannot be told apart and is also defaulted.
+func SetDefaults(v any) error {
+	var errs []error
+	walk(reflect.ValueOf(v), "", func(field reflect.StructField, fv reflect.Value, path string) {
+		def, ok := field.Tag.Lookup("default")
+		if !ok || !fv.IsZero() || !fv.CanSet() {
+			return
+		}
+		if err := setDefault(fv, def); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid default %q: %w", path, def, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// setDefault parses def into v.
+func setDefault(v reflect.Value, def string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Kind() == reflect.String:
+		v.SetString(def)
+		return nil
+	default:
+		return json.Unmarshal([]byte(def), v.Addr().Interface())
+	}
+}
+
+// Validate checks every field against the rules of its `validate` tag, walking nested
+// structs, pointers, slices, arrays and maps, and returns a *ValidationError for every
+// violation joined together. Rules are separated by commas:
+//
+//	required     the value must not be zero
+//	min=N, max=N bounds for numbers, or for the length of strings, slices and maps
+//	oneof=a|b|c  the value must be one of the listed values
+func Validate(v any) error {
+	var errs []error
+	walk(reflect.ValueOf(v), "", func(field reflect.StructField, fv reflect.Value, path string) {
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			return
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			if msg := check(fv, rule); msg != "" {
+				name, _, _ := strings.Cut(rule, "=")
+				errs = append(errs, &ValidationError{path, name, msg})
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// check returns a message describing how v violates rule, or "" if it does not.
+func check(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+	if name == "required" {
+		if v.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid rule %q", rule)
+		}
+		n, isLen, ok := measure(v)
+		if !ok {
+			return fmt.Sprintf("rule %q does not apply to %s", rule, v.Type())
+		}
+		what := "be"
+		if isLen {
+			what = "have length"
+		}
+		if name == "min" && n < limit {
+			return fmt.Sprintf("must %s at least %s", what, arg)
+		}
+		if name == "max" && n > limit {
+			return fmt.Sprintf("must %s at most %s", what, arg)
+		}
+	case "oneof":
+		value := fmt.Sprint(v.Interface())
+		for _, option := range strings.Split(arg, "|") {
+			if value == option {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(arg, "|", ", "))
+	default:
+		return fmt.Sprintf("unknown rule %q", rule)
+	}
+	return ""
+}
+
+// measure returns the number or length that min and max rules compare.
+func measure(v reflect.Value) (float64, bool, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true, true
+	default:
+		return 0, false, false
+	}
+}
+
+// walk calls fn for every struct field reachable from v, before descending into it.
+func walk(v reflect.Value, path string, fn func(reflect.StructField, reflect.Value, string)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walk(v.Elem(), path, fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			fv := v.Field(i)
+			if field.Anonymous && name == "" {
+				walk(fv, path, fn)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			p := name
+			if path != "" {
+				p = path + "." + name
+			}
+			fn(field, fv, p)
+			walk(fv, p, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			walk(elem, fmt.Sprintf("%s[%v]", path, iter.Key()), fn)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
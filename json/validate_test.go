@@ -0,0 +1,83 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testValidServer struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" default:"8080" validate:"min=1,max=65535"`
+}
+
+type testValidConfig struct {
+	Mode    string                     `json:"mode" default:"dev" validate:"oneof=dev|prod"`
+	Timeout time.Duration              `json:"timeout" default:"5s"`
+	Tags    []string                   `json:"tags" default:"[\"a\",\"b\"]" validate:"max=3"`
+	Servers []testValidServer          `json:"servers" validate:"required"`
+	Named   map[string]testValidServer `json:"named"`
+	Primary *testValidServer           `json:"primary"`
+}
+
+func TestSetDefaults(t *testing.T) {
+	c := testValidConfig{
+		Mode:    "prod",
+		Servers: []testValidServer{{Host: "a"}, {Host: "b", Port: 9000}},
+		Named:   map[string]testValidServer{"x": {Host: "x"}},
+	}
+	if err := SetDefaults(&c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if c.Mode != "prod" || c.Timeout != 5*time.Second || !reflect.DeepEqual(c.Tags, []string{"a", "b"}) {
+		t.Fatalf("unexpected top-level defaults %+v", c)
+	}
+	if c.Servers[0].Port != 8080 || c.Servers[1].Port != 9000 || c.Named["x"].Port != 8080 {
+		t.Fatalf("expected nested defaults, got %+v", c)
+	}
+	if c.Primary != nil {
+		t.Fatalf("expected nil pointer to be left alone")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := testValidConfig{
+		Mode:    "test",
+		Tags:    []string{"a", "b", "c", "d"},
+		Servers: []testValidServer{{Host: "a", Port: 80}, {Port: 70000}},
+		Primary: &testValidServer{Host: "p"},
+	}
+	err := Validate(&c)
+	expected := []string{
+		"mode: must be one of dev, prod",
+		"tags: must have length at most 3",
+		"servers[1].host: is required",
+		"servers[1].port: must be at most 65535",
+		"primary.port: must be at least 1",
+	}
+	if err == nil || err.Error() != strings.Join(expected, "\n") {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Rule != "oneof" {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+}
+
+func TestLoadFileWithOpts_Validate(t *testing.T) {
+	filename := writeTemp(t, "config.json", `{"servers": [{"host": "a"}]}`)
+	result, err := LoadFileWithOpts[testValidConfig](filename, &LoadOpts{Validate: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Mode != "dev" || result.Servers[0].Port != 8080 {
+		t.Fatalf("expected defaults to be applied, got %+v", result)
+	}
+	filename = writeTemp(t, "config.json", `{"mode": "prod"}`)
+	_, err = LoadFileWithOpts[testValidConfig](filename, &LoadOpts{Validate: true})
+	if err == nil || !strings.HasSuffix(err.Error(), "servers: is required") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
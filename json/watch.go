@@ -0,0 +1,158 @@
+package json
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WatchOpts defines the options for watching a json file.
+type WatchOpts struct {
+	// Interval is how often the file is checked for changes. Defaults to 1s.
+	Interval time.Duration
+	// Load is used to load the file. Set Validate to reject invalid changes.
+	Load *LoadOpts
+	// OnError is called when a reload fails. The last good value is kept.
+	OnError func(error)
+}
+
+// Watch keeps a value loaded from a json file up to date by polling it for changes.
+type Watch[T any] struct {
+	filename    string
+	opts        WatchOpts
+	value       atomic.Pointer[T]
+	mu          sync.Mutex
+	subscribers []func(old, new *T)
+	modTime     time.Time
+	size        int64
+	sum         [sha256.Size]byte
+	done        chan struct{}
+	closeOnce   sync.Once
+	wg          sync.WaitGroup
+}
+
+// NewWatch loads a json file and starts watching it for changes. The initial load must succeed.
+func NewWatch[T any](filename string, opts *WatchOpts) (*Watch[T], error) {
+	w := &Watch[T]{
+		filename: filepath.Clean(filename),
+		opts:     WatchOpts{Interval: time.Second},
+		done:     make(chan struct{}),
+	}
+	if opts != nil {
+		w.opts.Load = opts.Load
+		w.opts.OnError = opts.OnError
+		if opts.Interval > 0 {
+			w.opts.Interval = opts.Interval
+		}
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	w.wg.Add(1)
+	go w.poll()
+	return w, nil
+}
+
+// Get returns the current value. It must not be modified.
+func (w *Watch[T]) Get() *T {
+	return w.value.Load()
+}
+
+// Subscribe registers fn to be called with the old and new value after every change.
+// Subscribers are called in order, without holding the watch's lock, from the goroutine that
+// detected the change, so they may call Reload or Subscribe.
+func (w *Watch[T]) Subscribe(fn func(old, new *T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload loads the file now, keeping the current value if it fails. Subscribers are
+// notified if the contents changed.
+func (w *Watch[T]) Reload() error {
+	return w.update(func(os.FileInfo) bool { return true })
+}
+
+// Close stops watching the file. It is safe to call more than once.
+func (w *Watch[T]) Close() {
+	w.closeOnce.Do(func() { close(w.done) })
+	w.wg.Wait()
+}
+
+// poll checks the file for changes until the watch is closed.
+func (w *Watch[T]) poll() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.check(); err != nil && w.opts.OnError != nil {
+				w.opts.OnError(err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// check reloads the file if its modification time or size changed.
+func (w *Watch[T]) check() error {
+	return w.update(func(info os.FileInfo) bool {
+		return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+	})
+}
+
+// update reloads the file if changed reports true for its current info, then notifies the
+// subscribers after releasing the lock.
+func (w *Watch[T]) update(changed func(os.FileInfo) bool) error {
+	w.mu.Lock()
+	info, err := os.Stat(w.filename)
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	if !changed(info) {
+		w.mu.Unlock()
+		return nil
+	}
+	old, s, err := w.reload(info)
+	// capped so a concurrent Subscribe appends to a new array
+	subscribers := w.subscribers[:len(w.subscribers):len(w.subscribers)]
+	w.mu.Unlock()
+	if err != nil || old == nil || s == nil {
+		return err
+	}
+	for _, fn := range subscribers {
+		fn(old, s)
+	}
+	return nil
+}
+
+// reload loads the file and swaps in the new value if its contents changed, returning the
+// old and new values. Both are nil when nothing changed.
+func (w *Watch[T]) reload(info os.FileInfo) (*T, *T, error) {
+	data, err := readFile(w.filename, w.opts.Load)
+	if err != nil {
+		return nil, nil, err
+	}
+	sum := sha256.Sum256(data)
+	old := w.value.Load()
+	if old != nil && bytes.Equal(sum[:], w.sum[:]) {
+		w.modTime, w.size = info.ModTime(), info.Size()
+		return nil, nil, nil
+	}
+	var s T
+	if err = load(w.filename, data, &s, w.opts.Load); err != nil {
+		// don't retry until the file changes again
+		w.modTime, w.size = info.ModTime(), info.Size()
+		return nil, nil, err
+	}
+	w.value.Store(&s)
+	w.modTime, w.size, w.sum = info.ModTime(), info.Size(), sum
+	return old, &s, nil
+}
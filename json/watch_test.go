@@ -0,0 +1,97 @@
+package json
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	filename := writeTemp(t, "config.json", `{"servers": [{"host": "a"}]}`)
+	errs := make(chan error, 10)
+	w, err := NewWatch[testValidConfig](filename, &WatchOpts{
+		Interval: 10 * time.Millisecond,
+		Load:     &LoadOpts{Validate: true},
+		OnError:  func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer w.Close()
+	if w.Get().Servers[0].Host != "a" {
+		t.Fatalf("expected initial value, got %+v", w.Get())
+	}
+	changes := make(chan [2]*testValidConfig, 10)
+	w.Subscribe(func(old, new *testValidConfig) {
+		changes <- [2]*testValidConfig{old, new}
+	})
+
+	writeFile(t, filename, `{"servers": [{"host": "b"}]}`)
+	select {
+	case change := <-changes:
+		if change[0].Servers[0].Host != "a" || change[1].Servers[0].Host != "b" {
+			t.Fatalf("unexpected change %+v -> %+v", change[0], change[1])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected change notification")
+	}
+
+	writeFile(t, filename, `{"mode": "invalid", "servers": [{"host": "c"}]}`)
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected reload error")
+	}
+	if w.Get().Servers[0].Host != "b" {
+		t.Fatalf("expected last good value to be kept, got %+v", w.Get())
+	}
+	select {
+	case change := <-changes:
+		t.Fatalf("unexpected change notification %+v", change[1])
+	default:
+	}
+}
+
+// writeFile replaces a file's contents, ensuring its modification time changes.
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(filename, later, later); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWatch_SubscriberReloads(t *testing.T) {
+	filename := writeTemp(t, "config.json", `{"field1": "a"}`)
+	w, err := NewWatch[testStruct](filename, &WatchOpts{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer w.Close()
+	done := make(chan error, 1)
+	w.Subscribe(func(old, new *testStruct) {
+		w.Subscribe(func(old, new *testStruct) {})
+		done <- w.Reload()
+	})
+	writeFile(t, filename, `{"field1": "b"}`)
+	go func() {
+		if err := w.Reload(); err != nil {
+			done <- err
+		}
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscriber calling Reload deadlocked")
+	}
+	if w.Get().Field1 != "b" {
+		t.Fatalf("expected new value, got %+v", w.Get())
+	}
+	w.Close()
+}
@@ -0,0 +1,210 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// AuditWriter is a tamper-evident sink for a Logger. Each line written to it is prefixed
+// with a sequence number and a SHA-256 hash (HMAC-SHA256 when keyed) chaining it to the
+// previous record. Use it as Opts.Output.
+type AuditWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	key  []byte
+	seq  uint64
+	prev []byte
+	buf  []byte
+	// err is set once a record was only partly written, which breaks the chain for good.
+	err error
+}
+
+// AuditError describes the first record of an audit log that failed verification.
+type AuditError struct {
+	Line   int
+	Seq    uint64
+	Reason string
+}
+
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("audit log line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// NewAuditWriter creates an AuditWriter that starts a new chain on w. key may be nil.
+func NewAuditWriter(w io.Writer, key []byte) *AuditWriter {
+	return &AuditWriter{
+		w:    w,
+		key:  key,
+		prev: make([]byte, sha256.Size),
+	}
+}
+
+// OpenAuditFile verifies an existing audit file and opens it to continue its chain.
+// The file is created if it does not exist.
+func OpenAuditFile(filename string, key []byte) (*AuditWriter, error) {
+	file, err := os.OpenFile(filepath.Clean(filename), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	seq, prev, err := verifyAudit(file, key)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	a := NewAuditWriter(file, key)
+	a.seq = seq
+	if prev != nil {
+		a.prev = prev
+	}
+	return a, nil
+}
+
+// Write chains every complete line in p as a record. Partial lines are held until completed.
+// Write always consumes all of p: if a record cannot be written, its line is dropped without
+// advancing the chain and the error is returned. Once a record has been partly written, the
+// log can no longer be verified past it and every later Write and Close returns that error.
+func (a *AuditWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return 0, a.err
+	}
+	a.buf = append(a.buf, p...)
+	for {
+		i := bytes.IndexByte(a.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := a.buf[:i]
+		a.buf = a.buf[i+1:]
+		if err := a.record(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Close records any pending partial line and closes the underlying writer if it is an io.Closer.
+func (a *AuditWriter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := a.err
+	if err == nil && len(a.buf) > 0 {
+		err = a.record(a.buf)
+		a.buf = nil
+	}
+	if c, ok := a.w.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// record writes a single chained record. The chain only advances once the write succeeds,
+// so a failed write does not leave a gap.
+func (a *AuditWriter) record(line []byte) error {
+	seq := a.seq + 1
+	sum := auditHash(a.key, a.prev, seq, line)
+	rec := make([]byte, 0, 24+2*len(sum)+len(line))
+	rec = strconv.AppendUint(rec, seq, 10)
+	rec = append(rec, ' ')
+	rec = append(rec, hex.EncodeToString(sum)...)
+	rec = append(rec, ' ')
+	rec = append(rec, line...)
+	rec = append(rec, '\n')
+	n, err := a.w.Write(rec)
+	if err == nil && n < len(rec) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		if n > 0 {
+			a.err = fmt.Errorf("audit record %d partly written: %w", seq, err)
+			return a.err
+		}
+		return err
+	}
+	a.seq, a.prev = seq, sum
+	return nil
+}
+
+// VerifyAudit walks an audit log and returns the number of valid records. The returned
+// error is an *AuditError describing the first broken or missing record.
+func VerifyAudit(r io.Reader, key []byte) (uint64, error) {
+	seq, _, err := verifyAudit(r, key)
+	return seq, err
+}
+
+// VerifyAuditFile verifies the audit log stored in filename.
+func VerifyAuditFile(filename string, key []byte) (uint64, error) {
+	file, err := os.Open(filepath.Clean(filename))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return VerifyAudit(file, key)
+}
+
+// verifyAudit returns the sequence number and hash of the last valid record.
+func verifyAudit(r io.Reader, key []byte) (uint64, []byte, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var seq uint64
+	var prev []byte
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := bytes.SplitN(scanner.Bytes(), []byte(" "), 3)
+		if len(fields) < 2 {
+			return seq, prev, &AuditError{line, seq + 1, "malformed record"}
+		}
+		got, err := strconv.ParseUint(string(fields[0]), 10, 64)
+		if err != nil {
+			return seq, prev, &AuditError{line, seq + 1, "malformed sequence number"}
+		}
+		if got != seq+1 {
+			return seq, prev, &AuditError{line, seq + 1, fmt.Sprintf("expected sequence %d, got %d", seq+1, got)}
+		}
+		sum, err := hex.DecodeString(string(fields[1]))
+		if err != nil {
+			return seq, prev, &AuditError{line, got, "malformed hash"}
+		}
+		if prev == nil {
+			prev = make([]byte, sha256.Size)
+		}
+		var msg []byte
+		if len(fields) == 3 {
+			msg = fields[2]
+		}
+		if !hmac.Equal(sum, auditHash(key, prev, got, msg)) {
+			return seq, prev, &AuditError{line, got, "hash mismatch"}
+		}
+		seq, prev = got, sum
+	}
+	return seq, prev, scanner.Err()
+}
+
+// auditHash chains a record to the previous hash.
+func auditHash(key, prev []byte, seq uint64, line []byte) []byte {
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(prev)
+	h.Write([]byte(strconv.FormatUint(seq, 10)))
+	h.Write([]byte{' '})
+	h.Write(line)
+	return h.Sum(nil)
+}
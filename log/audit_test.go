@@ -0,0 +1,175 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failOnce is a writer that fails its first write.
+type failOnce struct {
+	bytes.Buffer
+	failed bool
+}
+
+func (w *failOnce) Write(p []byte) (int, error) {
+	if !w.failed {
+		w.failed = true
+		return 0, errors.New("disk full")
+	}
+	return w.Buffer.Write(p)
+}
+
+func writeAudit(t *testing.T, a *AuditWriter, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := a.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+func TestAuditVerify(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewAuditWriter(&buf, nil)
+	writeAudit(t, a, "one", "two")
+	if _, err := a.Write([]byte("thr")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	n, err := VerifyAudit(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 valid records, got %d, %v", n, err)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), " thr") {
+		t.Fatalf("expected partial line to be recorded on Close, got %q", buf.String())
+	}
+}
+
+func TestAuditTampered(t *testing.T) {
+	var buf bytes.Buffer
+	writeAudit(t, NewAuditWriter(&buf, nil), "one", "two", "three")
+	tampered := strings.Replace(buf.String(), " two\n", " 2wo\n", 1)
+	n, err := VerifyAudit(strings.NewReader(tampered), nil)
+	var aerr *AuditError
+	if !errors.As(err, &aerr) || aerr.Line != 2 || aerr.Reason != "hash mismatch" || n != 1 {
+		t.Fatalf("expected hash mismatch on line 2, got %d, %v", n, err)
+	}
+}
+
+func TestAuditGap(t *testing.T) {
+	var buf bytes.Buffer
+	writeAudit(t, NewAuditWriter(&buf, nil), "one", "two", "three")
+	lines := strings.SplitAfter(buf.String(), "\n")
+	n, err := VerifyAudit(strings.NewReader(lines[0]+lines[2]), nil)
+	var aerr *AuditError
+	if !errors.As(err, &aerr) || aerr.Line != 2 || !strings.Contains(aerr.Reason, "expected sequence 2, got 3") || n != 1 {
+		t.Fatalf("expected sequence gap on line 2, got %d, %v", n, err)
+	}
+}
+
+func TestAuditFailedWrite(t *testing.T) {
+	w := &failOnce{}
+	a := NewAuditWriter(w, nil)
+	if _, err := a.Write([]byte("lost\n")); err == nil {
+		t.Fatal("expected write error")
+	}
+	writeAudit(t, a, "one", "two")
+	n, err := VerifyAudit(bytes.NewReader(w.Bytes()), nil)
+	if err != nil || n != 2 {
+		t.Fatalf("expected chain to continue after a failed write, got %d, %v", n, err)
+	}
+}
+
+func TestAuditKey(t *testing.T) {
+	var buf bytes.Buffer
+	writeAudit(t, NewAuditWriter(&buf, []byte("secret")), "one", "two")
+	if n, err := VerifyAudit(bytes.NewReader(buf.Bytes()), []byte("secret")); err != nil || n != 2 {
+		t.Fatalf("expected 2 valid records, got %d, %v", n, err)
+	}
+	for _, key := range [][]byte{[]byte("wrong"), nil} {
+		_, err := VerifyAudit(bytes.NewReader(buf.Bytes()), key)
+		var aerr *AuditError
+		if !errors.As(err, &aerr) || aerr.Line != 1 || aerr.Reason != "hash mismatch" {
+			t.Fatalf("expected hash mismatch with key %q, got %v", key, err)
+		}
+	}
+}
+
+func TestOpenAuditFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("secret")
+	a, err := OpenAuditFile(filename, key)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	writeAudit(t, a, "one", "two")
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if a, err = OpenAuditFile(filename, key); err != nil {
+		t.Fatalf("expected no error on resume, got %v", err)
+	}
+	writeAudit(t, a, "three")
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := VerifyAuditFile(filename, key); err != nil || n != 3 {
+		t.Fatalf("expected resumed chain of 3 records, got %d, %v", n, err)
+	}
+	if _, err = OpenAuditFile(filename, []byte("wrong")); err == nil {
+		t.Fatal("expected resume with the wrong key to fail")
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filename, bytes.Replace(data, []byte("two"), []byte("TWO"), 1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var aerr *AuditError
+	if _, err = OpenAuditFile(filename, key); !errors.As(err, &aerr) {
+		t.Fatalf("expected resume of a tampered file to fail, got %v", err)
+	}
+}
+
+// failPartial is a writer that writes half of its second write and then fails.
+type failPartial struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *failPartial) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes == 2 {
+		n, _ := w.Buffer.Write(p[:len(p)/2])
+		return n, errors.New("disk full")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestAuditPartialWrite(t *testing.T) {
+	w := &failPartial{}
+	a := NewAuditWriter(w, nil)
+	writeAudit(t, a, "one")
+	if _, err := a.Write([]byte("two\n")); err == nil {
+		t.Fatal("expected write error")
+	}
+	if n, err := a.Write([]byte("three\n")); err == nil || n != 0 || !strings.Contains(err.Error(), "partly written") {
+		t.Fatalf("expected later writes to report the partial record, got %d, %v", n, err)
+	}
+	if err := a.Close(); err == nil || !strings.Contains(err.Error(), "partly written") {
+		t.Fatalf("expected Close to report the partial record, got %v", err)
+	}
+	if w.writes != 2 {
+		t.Fatalf("expected nothing written after the partial record, got %d writes", w.writes)
+	}
+	if n, err := VerifyAudit(bytes.NewReader(w.Bytes()), nil); err == nil || n != 1 {
+		t.Fatalf("expected verification to stop at the partial record, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,298 @@
+// Command logq filters log files written by log.Logger.
+//
+// Usage:
+//
+//	logq [flags] [file ...]
+//
+// With no files, or a file named "-", logq reads standard input.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doggystylez/utils/log"
+)
+
+// filter holds the query built from the command line flags.
+type filter struct {
+	since  time.Time
+	until  time.Time
+	level  log.Level
+	labels map[string]struct{}
+	grep   *regexp.Regexp
+}
+
+// printer writes matching records to stdout.
+type printer struct {
+	mu   sync.Mutex
+	json bool
+	out  *bufio.Writer
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "logq:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	since := flag.String("since", "", "only show records at or after this time (RFC3339, log time format, or a duration ago such as 1h)")
+	until := flag.String("until", "", "only show records before this time (same formats as -since)")
+	level := flag.String("level", "", "minimum level to show (debug, info, error, fatal)")
+	labels := flag.String("label", "", "comma-separated labels to show")
+	grep := flag.String("grep", "", "regular expression the message must match")
+	follow := flag.Bool("f", false, "follow files as they grow")
+	asJSON := flag.Bool("json", false, "output records as JSON")
+	flag.Parse()
+
+	var f filter
+	var err error
+	if f.since, err = parseTime(*since); err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	if f.until, err = parseTime(*until); err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+	if *level != "" {
+		if err = f.level.UnmarshalText([]byte(*level)); err != nil {
+			return err
+		}
+	}
+	if *labels != "" {
+		f.labels = make(map[string]struct{})
+		for _, label := range strings.Split(*labels, ",") {
+			f.labels[strings.TrimSpace(label)] = struct{}{}
+		}
+	}
+	if *grep != "" {
+		if f.grep, err = regexp.Compile(*grep); err != nil {
+			return err
+		}
+	}
+
+	p := &printer{json: *asJSON, out: bufio.NewWriter(os.Stdout)}
+	defer p.out.Flush()
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	if !*follow {
+		for _, file := range files {
+			if err = query(file, &f, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(files))
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			errs[i] = tail(file, &f, p)
+		}(i, file)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// parseTime parses an absolute time or a duration before now.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006/01/02 15:04:05", s)
+}
+
+// query prints the matching records of a file.
+func query(file string, f *filter, p *printer) error {
+	if file == "-" {
+		return scan(os.Stdin, f, p)
+	}
+	r, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return scan(r, f, p)
+}
+
+// scan prints the matching records read from r.
+func scan(r io.Reader, f *filter, p *printer) error {
+	records, err := log.Parse(r)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if f.match(record) {
+			p.print(record)
+		}
+	}
+	return nil
+}
+
+// tail prints matching records of a file as they are appended, like tail -f.
+func tail(file string, f *filter, p *printer) error {
+	if file == "-" {
+		return scanFollow(bufio.NewReader(os.Stdin), f, p, nil)
+	}
+	for {
+		r, err := os.Open(filepath.Clean(file))
+		if err != nil {
+			return err
+		}
+		err = scanFollow(bufio.NewReader(r), f, p, func() bool { return truncated(r, file) })
+		_ = r.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// scanFollow reads lines from r forever, polling at EOF. It returns nil when reopen
+// reports that the file should be opened again.
+func scanFollow(r *bufio.Reader, f *filter, p *printer, reopen func() bool) error {
+	fl := &follower{f: f, p: p}
+	var partial string
+	for {
+		line, err := r.ReadString('\n')
+		partial += line
+		if err == io.EOF && reopen == nil {
+			// stdin cannot grow once it reaches EOF
+			if partial != "" {
+				fl.line(partial)
+			}
+			fl.flush()
+			return nil
+		}
+		if err == io.EOF {
+			// the logger writes each entry, continuation lines included, at once, so the
+			// pending record is complete unless a line was cut short
+			if partial == "" {
+				fl.flush()
+			}
+			p.flush()
+			time.Sleep(250 * time.Millisecond)
+			if reopen != nil && reopen() {
+				return nil
+			}
+			continue
+		}
+		if err != nil {
+			fl.flush()
+			return err
+		}
+		fl.line(partial)
+		partial = ""
+	}
+}
+
+// truncated reports whether the file was truncated or replaced since it was opened.
+func truncated(r *os.File, file string) bool {
+	pos, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false
+	}
+	opened, err := r.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(file)
+	if err != nil {
+		return false
+	}
+	return !os.SameFile(opened, current) || current.Size() < pos
+}
+
+// match reports whether the record satisfies the filter.
+func (f *filter) match(r log.Record) bool {
+	if !f.since.IsZero() && r.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !r.Time.Before(f.until) {
+		return false
+	}
+	if r.Level < f.level {
+		return false
+	}
+	if f.labels != nil {
+		if _, ok := f.labels[r.Label]; !ok {
+			return false
+		}
+	}
+	return f.grep == nil || f.grep.MatchString(r.Msg)
+}
+
+// print writes a record in the selected format.
+func (p *printer) print(r log.Record) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.json {
+		b, err := json.Marshal(r)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "logq:", err)
+			return
+		}
+		p.out.Write(b)
+		p.out.WriteByte('\n')
+		return
+	}
+	p.out.WriteString(r.String())
+	p.out.WriteByte('\n')
+}
+
+// follower groups followed lines into records as log.Parse does, holding the last record
+// so that continuation lines, such as a stack trace, are appended to its message.
+type follower struct {
+	f       *filter
+	p       *printer
+	pending *log.Record
+}
+
+// line adds a line to the pending record, or prints the pending record if line starts a new one.
+func (fl *follower) line(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	record, err := log.ParseLine(line)
+	if err != nil {
+		if fl.pending != nil {
+			fl.pending.Msg += "\n" + line
+		}
+		return
+	}
+	fl.flush()
+	fl.pending = &record
+}
+
+// flush prints the pending record if it matches the filter.
+func (fl *follower) flush() {
+	if fl.pending != nil && fl.f.match(*fl.pending) {
+		fl.p.print(*fl.pending)
+	}
+	fl.pending = nil
+}
+
+// flush writes buffered output while waiting for more input.
+func (p *printer) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_ = p.out.Flush()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doggystylez/utils/log"
+)
+
+func TestParseTime(t *testing.T) {
+	for _, s := range []string{"2024-01-02T03:04:05Z", "2024/01/02 03:04:05"} {
+		got, err := parseTime(s)
+		if err != nil || !got.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+			t.Errorf("parseTime(%q) = %v, %v", s, got, err)
+		}
+	}
+	got, err := parseTime("1h")
+	if err != nil || time.Since(got) < time.Hour || time.Since(got) > time.Hour+time.Minute {
+		t.Errorf("expected an hour ago, got %v, %v", got, err)
+	}
+	if got, err = parseTime(""); err != nil || !got.IsZero() {
+		t.Errorf("expected zero time, got %v, %v", got, err)
+	}
+	if _, err = parseTime("yesterday"); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := log.Record{Time: at, Level: log.Info, Label: "http", Msg: "GET /index"}
+	tests := []struct {
+		name string
+		f    filter
+		want bool
+	}{
+		{"empty", filter{}, true},
+		{"since", filter{since: at}, true},
+		{"since after", filter{since: at.Add(time.Second)}, false},
+		{"until", filter{until: at}, false},
+		{"until after", filter{until: at.Add(time.Second)}, true},
+		{"level", filter{level: log.Info}, true},
+		{"level above", filter{level: log.Error}, false},
+		{"label", filter{labels: map[string]struct{}{"http": {}}}, true},
+		{"other label", filter{labels: map[string]struct{}{"db": {}}}, false},
+		{"grep", filter{grep: regexp.MustCompile(`^GET`)}, true},
+		{"grep miss", filter{grep: regexp.MustCompile(`POST`)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.f.match(record); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestScanFollowContinuation(t *testing.T) {
+	input := "2024/01/02 03:04:05 [ERR] [worker] panic: boom\n" +
+		"goroutine 1 [running]:\n" +
+		"main.main()\n" +
+		"2024/01/02 03:04:06 [INF] [worker] restarting\n"
+	var out bytes.Buffer
+	p := &printer{out: bufio.NewWriter(&out)}
+	if err := scanFollow(bufio.NewReader(strings.NewReader(input)), &filter{}, p, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	p.out.Flush()
+	records, err := log.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want strings.Builder
+	for _, r := range records {
+		want.WriteString(r.String() + "\n")
+	}
+	if out.String() != want.String() {
+		t.Fatalf("expected follow output to match Parse:\n%s\ngot:\n%s", want.String(), out.String())
+	}
+	if !strings.Contains(out.String(), "boom\ngoroutine 1 [running]:\nmain.main()") {
+		t.Fatalf("expected stack trace to be kept, got %q", out.String())
+	}
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// repeat tracks an entry that has been written and the duplicates suppressed since.
+type repeat struct {
+	entry Log
+	start time.Time
+	last  time.Time
+	count int
+}
+
+// dedup collapses identical log entries that occur within a window.
+type dedup struct {
+	window time.Duration
+	seen   map[string]*repeat
+}
+
+// newDedup creates a new dedup stage with the given window.
+func newDedup(window time.Duration) *dedup {
+	return &dedup{
+		window: window,
+		seen:   make(map[string]*repeat),
+	}
+}
+
+// dedupKey identifies entries with the same level, label and message.
+func dedupKey(entry Log) string {
+	return fmt.Sprint(entry.level) + "\x00" + entry.label + "\x00" + fmt.Sprintln(entry.msg...)
+}
+
+// add returns the entries that should be written for the incoming entry.
+func (d *dedup) add(entry Log) []Log {
+	key := dedupKey(entry)
+	r, exists := d.seen[key]
+	if exists && entry.time.Sub(r.start) < d.window {
+		r.count++
+		r.last = entry.time
+		return nil
+	}
+	var out []Log
+	if exists && r.count > 0 {
+		out = append(out, r.summary())
+	}
+	d.seen[key] = &repeat{entry: entry, start: entry.time, last: entry.time}
+	return append(out, entry)
+}
+
+// expire returns summaries for windows that have ended and forgets them.
+func (d *dedup) expire(now time.Time) []Log {
+	var expired []*repeat
+	for key, r := range d.seen {
+		if now.Sub(r.start) >= d.window {
+			if r.count > 0 {
+				expired = append(expired, r)
+			}
+			delete(d.seen, key)
+		}
+	}
+	return summaries(expired)
+}
+
+// flush returns summaries for all pending duplicates and resets the stage.
+func (d *dedup) flush() []Log {
+	pending := make([]*repeat, 0, len(d.seen))
+	for _, r := range d.seen {
+		if r.count > 0 {
+			pending = append(pending, r)
+		}
+	}
+	clear(d.seen)
+	return summaries(pending)
+}
+
+// summary builds the "repeated N times" entry for suppressed duplicates.
+func (r *repeat) summary() Log {
+	msg := make([]any, len(r.entry.msg), len(r.entry.msg)+1)
+	copy(msg, r.entry.msg)
+	msg = append(msg, fmt.Sprintf("(repeated %d times)", r.count))
+	return Log{r.last, r.entry.level, r.entry.label, msg}
+}
+
+// summaries returns the summary entries ordered by the time their window started.
+func summaries(repeats []*repeat) []Log {
+	slices.SortFunc(repeats, func(a, b *repeat) int {
+		return a.start.Compare(b.start)
+	})
+	out := make([]Log, len(repeats))
+	for i, r := range repeats {
+		out[i] = r.summary()
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer that is safe to read while the logger writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+var testStart = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testEntry(offset time.Duration, label string, msg ...any) Log {
+	return Log{testStart.Add(offset), Info, label, msg}
+}
+
+func TestDedupAdd(t *testing.T) {
+	d := newDedup(time.Second)
+	if out := d.add(testEntry(0, "a", "hello")); len(out) != 1 {
+		t.Fatalf("expected first entry to be written, got %v", out)
+	}
+	for i := 1; i <= 3; i++ {
+		if out := d.add(testEntry(time.Duration(i)*100*time.Millisecond, "a", "hello")); len(out) != 0 {
+			t.Fatalf("expected duplicate %d to be suppressed, got %v", i, out)
+		}
+	}
+	if out := d.add(testEntry(200*time.Millisecond, "b", "hello")); len(out) != 1 {
+		t.Fatalf("expected entry with another label to be written, got %v", out)
+	}
+	out := d.add(testEntry(time.Second, "a", "hello"))
+	if len(out) != 2 {
+		t.Fatalf("expected summary and new entry after the window, got %v", out)
+	}
+	if got := out[0].msg[len(out[0].msg)-1]; got != "(repeated 3 times)" {
+		t.Fatalf("unexpected summary %v", got)
+	}
+	if !out[0].time.Equal(testStart.Add(300 * time.Millisecond)) {
+		t.Fatalf("expected summary at the last duplicate, got %v", out[0].time)
+	}
+	if out[1].time != testStart.Add(time.Second) || len(out[1].msg) != 1 {
+		t.Fatalf("unexpected new entry %v", out[1])
+	}
+}
+
+func TestDedupExpire(t *testing.T) {
+	d := newDedup(time.Second)
+	d.add(testEntry(0, "a", "one"))
+	d.add(testEntry(100*time.Millisecond, "a", "one"))
+	d.add(testEntry(500*time.Millisecond, "b", "two"))
+	d.add(testEntry(600*time.Millisecond, "b", "two"))
+	d.add(testEntry(700*time.Millisecond, "c", "single"))
+	if out := d.expire(testStart.Add(900 * time.Millisecond)); len(out) != 0 {
+		t.Fatalf("expected no summaries before the window ends, got %v", out)
+	}
+	out := d.expire(testStart.Add(time.Second))
+	if len(out) != 1 || out[0].label != "a" {
+		t.Fatalf("expected summary for a, got %v", out)
+	}
+	out = d.expire(testStart.Add(2 * time.Second))
+	if len(out) != 1 || out[0].label != "b" {
+		t.Fatalf("expected summary for b only, got %v", out)
+	}
+	if len(d.seen) != 0 {
+		t.Fatalf("expected expired entries to be forgotten, got %d", len(d.seen))
+	}
+	if out := d.add(testEntry(2*time.Second, "a", "one")); len(out) != 1 {
+		t.Fatalf("expected entry after expiry to be written alone, got %v", out)
+	}
+}
+
+func TestDedupFlush(t *testing.T) {
+	d := newDedup(time.Minute)
+	d.add(testEntry(3*time.Second, "late", "x"))
+	d.add(testEntry(4*time.Second, "late", "x"))
+	d.add(testEntry(time.Second, "early", "y"))
+	d.add(testEntry(2*time.Second, "early", "y"))
+	d.add(testEntry(5*time.Second, "single", "z"))
+	out := d.flush()
+	if len(out) != 2 || out[0].label != "early" || out[1].label != "late" {
+		t.Fatalf("expected summaries ordered by window start, got %v", out)
+	}
+	if len(d.seen) != 0 || len(d.flush()) != 0 {
+		t.Fatal("expected flush to reset the stage")
+	}
+}
+
+func TestLoggerDedup(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(&Opts{Output: &buf, DedupWindow: 50 * time.Millisecond})
+	for i := 0; i < 3; i++ {
+		logger.Info("test", "tick")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "(repeated 2 times)") {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the ticker to write a summary, got %q", buf.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	logger.Info("test", "tock")
+	logger.Info("test", "tock")
+	logger.Shutdown()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", lines)
+	}
+	if !strings.HasSuffix(lines[0], "[test] tick") || !strings.HasSuffix(lines[1], "tick (repeated 2 times)") {
+		t.Fatalf("unexpected ticker output %q", lines[:2])
+	}
+	if !strings.HasSuffix(lines[2], "[test] tock") || !strings.HasSuffix(lines[3], "tock (repeated 1 times)") {
+		t.Fatalf("expected Shutdown to flush pending summaries, got %q", lines[2:])
+	}
+}
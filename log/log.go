@@ -1,6 +1,7 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -19,6 +20,7 @@ const (
 	Debug
 	Info
 	Error
+	Fatal
 )
 
 // String representations of log levels
@@ -26,6 +28,7 @@ var levelStrings = []string{
 	Debug: "[DBG]",
 	Info:  "[INF]",
 	Error: "[ERR]",
+	Fatal: "[FTL]",
 }
 
 // ANSI color codes for log levels
@@ -33,6 +36,7 @@ var levelColors = []string{
 	Debug: "[\033[33mDBG\033[0m]",
 	Info:  "[\033[32mINF\033[0m]",
 	Error: "[\033[31mERR\033[0m]",
+	Fatal: "[\033[35mFTL\033[0m]",
 }
 
 const timeFormat = "2006/01/02 15:04:05"
@@ -47,6 +51,11 @@ type Logger struct {
 	sb      strings.Builder
 	done    chan struct{}
 	wg      sync.WaitGroup
+	dedup   *dedup
+	json    bool
+	slow    time.Duration
+	closed  bool
+	once    sync.Once
 }
 
 // Log represents a log entry.
@@ -63,6 +72,14 @@ type Opts struct {
 	Color     bool
 	BufferLen int
 	Output    io.Writer
+	// JSON writes each entry as a JSON object instead of text. Color is ignored.
+	JSON bool
+	// DedupWindow collapses identical entries logged within the window into a single
+	// "repeated N times" line. Zero disables deduplication.
+	DedupWindow time.Duration
+	// SlowThreshold logs spans that take at least this long at Error instead of Info.
+	// Zero disables the threshold.
+	SlowThreshold time.Duration
 }
 
 // ParseString parses a string into a log level.
@@ -74,6 +91,8 @@ func ParseString(level string) Level {
 		return Info
 	case "error":
 		return Error
+	case "fatal":
+		return Fatal
 	default:
 		return Info
 	}
@@ -92,12 +111,17 @@ func NewLogger(opts *Opts) *Logger {
 			logger.level = ParseString(opts.Level)
 		}
 		logger.color = opts.Color
+		logger.json = opts.JSON
 		if opts.BufferLen != 0 {
 			logger.logChan = make(chan Log, opts.BufferLen)
 		}
 		if opts.Output != nil {
 			logger.output = opts.Output
 		}
+		if opts.DedupWindow > 0 {
+			logger.dedup = newDedup(opts.DedupWindow)
+		}
+		logger.slow = opts.SlowThreshold
 	}
 	logger.wg.Add(1)
 	go logger.processLogs()
@@ -107,22 +131,53 @@ func NewLogger(opts *Opts) *Logger {
 // processLogs handles log messages from the channel in its own goroutine.
 func (l *Logger) processLogs() {
 	defer l.wg.Done()
+	var tick <-chan time.Time
+	if l.dedup != nil {
+		ticker := time.NewTicker(l.dedup.window)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 	for {
 		select {
 		case entry := <-l.logChan:
-			l.write(entry)
+			l.handle(entry)
+		case now := <-tick:
+			for _, entry := range l.dedup.expire(now.UTC()) {
+				l.write(entry)
+			}
 		case <-l.done:
 			for entry := range l.logChan {
-				l.write(entry)
+				l.handle(entry)
+			}
+			if l.dedup != nil {
+				for _, entry := range l.dedup.flush() {
+					l.write(entry)
+				}
 			}
 			return
 		}
 	}
 }
 
+// handle passes the log entry through deduplication, if enabled, and writes it.
+func (l *Logger) handle(entry Log) {
+	if l.dedup == nil {
+		l.write(entry)
+		return
+	}
+	for _, e := range l.dedup.add(entry) {
+		l.write(e)
+	}
+}
+
 // write outputs the log entry to the writer.
 func (l *Logger) write(entry Log) {
 	l.mu.Lock()
+	if l.json {
+		l.writeJSON(entry)
+		l.mu.Unlock()
+		return
+	}
 	l.sb.WriteString(entry.time.Format(timeFormat))
 	l.sb.WriteString(" ")
 	if l.color {
@@ -142,6 +197,21 @@ func (l *Logger) write(entry Log) {
 	l.mu.Unlock()
 }
 
+// writeJSON outputs the log entry to the writer as a JSON object.
+func (l *Logger) writeJSON(entry Log) {
+	for _, m := range entry.msg {
+		l.sb.WriteString(fmt.Sprint(m))
+		l.sb.WriteString(" ")
+	}
+	b, err := json.Marshal(Record{entry.time, entry.level, entry.label, strings.TrimSpace(l.sb.String())})
+	l.sb.Reset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Logger failed to encode log message: %v\n", err)
+		return
+	}
+	fmt.Fprintln(l.output, string(b))
+}
+
 // Debug logs a debug message.
 func (l *Logger) Debug(label string, msg ...any) {
 	l.log(Debug, label, msg...)
@@ -157,12 +227,19 @@ func (l *Logger) Error(label string, msg ...any) {
 	l.log(Error, label, msg...)
 }
 
+// Fatal logs a fatal message, waits for the log queue to be processed and exits the program.
+func (l *Logger) Fatal(label string, msg ...any) {
+	l.log(Fatal, label, msg...)
+	l.Shutdown()
+	os.Exit(1)
+}
+
 // log checks the log level and enqueues the log message if appropriate.
 func (l *Logger) log(level Level, label string, msg ...any) {
+	// the read lock is held while enqueueing so Shutdown cannot close the channel mid-send
 	l.mu.RLock()
-	levelOK := level >= l.level
-	l.mu.RUnlock()
-	if !levelOK {
+	defer l.mu.RUnlock()
+	if l.closed || level < l.level {
 		return
 	}
 	select {
@@ -172,10 +249,16 @@ func (l *Logger) log(level Level, label string, msg ...any) {
 	}
 }
 
-// Shutdown waits for the log queue to be processed and ceases logging.
+// Shutdown waits for the log queue to be processed and ceases logging. It is safe to call
+// more than once and concurrently; messages logged afterwards are dropped.
 func (l *Logger) Shutdown() {
-	l.done <- struct{}{}
-	close(l.done)
-	close(l.logChan)
-	l.wg.Wait()
+	l.once.Do(func() {
+		l.mu.Lock()
+		l.closed = true
+		l.mu.Unlock()
+		l.done <- struct{}{}
+		close(l.done)
+		close(l.logChan)
+		l.wg.Wait()
+	})
 }
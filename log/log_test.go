@@ -0,0 +1,33 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShutdownConcurrent(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(&Opts{Output: &buf, BufferLen: 1000})
+	logger.Info("test", "before")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Error("test", "racing")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			logger.Shutdown()
+		}()
+	}
+	wg.Wait()
+	logger.Shutdown()
+	logger.Info("test", "after")
+	if out := buf.String(); !strings.Contains(out, "[test] before") || strings.Contains(out, "after") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
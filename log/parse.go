@@ -0,0 +1,162 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Names of log levels used by the JSON format
+var levelNames = []string{
+	Debug: "debug",
+	Info:  "info",
+	Error: "error",
+	Fatal: "fatal",
+}
+
+// Record is a log entry as written by a Logger, parsed back from its output.
+type Record struct {
+	Time  time.Time `json:"time"`
+	Level Level     `json:"level"`
+	Label string    `json:"label"`
+	Msg   string    `json:"msg"`
+}
+
+// String returns the name of the level.
+func (l Level) String() string {
+	if l > none && int(l) < len(levelNames) {
+		return levelNames[l]
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	if l <= none || int(l) >= len(levelNames) {
+		return nil, fmt.Errorf("invalid log level %d", int(l))
+	}
+	return []byte(levelNames[l]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unlike ParseString, unknown names are an error.
+func (l *Level) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	for level, n := range levelNames {
+		if n != "" && n == name {
+			*l = Level(level)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %q", text)
+}
+
+// String formats the record in the Logger's text format, without color.
+func (r Record) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Time.Format(timeFormat))
+	sb.WriteString(" ")
+	sb.WriteString(levelStrings[r.Level])
+	sb.WriteString(" [")
+	sb.WriteString(r.Label)
+	sb.WriteString("] ")
+	sb.WriteString(r.Msg)
+	return strings.TrimSpace(sb.String())
+}
+
+// ParseLine parses a single line of Logger output in either the text or JSON format.
+// Lines written through an AuditWriter are accepted and their audit prefix is ignored.
+func ParseLine(line string) (Record, error) {
+	line = stripAudit(strings.TrimRight(line, "\r\n"))
+	if strings.HasPrefix(line, "{") {
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return Record{}, err
+		}
+		return r, nil
+	}
+	return parseText(line)
+}
+
+// Parse reads all records from r. Lines that are not records, such as the continuation of a
+// multi-line message, are appended to the message of the preceding record.
+func Parse(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		record, err := ParseLine(scanner.Text())
+		if err != nil {
+			if len(records) == 0 {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			records[len(records)-1].Msg += "\n" + scanner.Text()
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// parseText parses a line in the text format.
+func parseText(line string) (Record, error) {
+	var r Record
+	if len(line) < len(timeFormat) {
+		return r, fmt.Errorf("invalid log line %q", line)
+	}
+	t, err := time.Parse(timeFormat, line[:len(timeFormat)])
+	if err != nil {
+		return r, err
+	}
+	r.Time = t
+	rest, ok := strings.CutPrefix(line[len(timeFormat):], " ")
+	if !ok {
+		return r, fmt.Errorf("invalid log line %q", line)
+	}
+	r.Level, rest, ok = cutLevel(rest)
+	if !ok {
+		return r, fmt.Errorf("invalid log level in %q", line)
+	}
+	rest, ok = strings.CutPrefix(rest, " [")
+	if !ok {
+		return r, fmt.Errorf("missing label in %q", line)
+	}
+	r.Label, r.Msg, ok = strings.Cut(rest, "]")
+	if !ok {
+		return r, fmt.Errorf("missing label in %q", line)
+	}
+	r.Msg = strings.TrimPrefix(r.Msg, " ")
+	return r, nil
+}
+
+// cutLevel removes a plain or colored level tag from the start of s.
+func cutLevel(s string) (Level, string, bool) {
+	for level := range levelStrings {
+		for _, tag := range []string{levelStrings[level], levelColors[level]} {
+			if tag == "" {
+				continue
+			}
+			if rest, ok := strings.CutPrefix(s, tag); ok {
+				return Level(level), rest, true
+			}
+		}
+	}
+	return none, s, false
+}
+
+// stripAudit removes the sequence number and hash written by an AuditWriter.
+func stripAudit(line string) string {
+	seq, rest, ok := strings.Cut(line, " ")
+	if !ok || seq == "" || strings.Trim(seq, "0123456789") != "" {
+		return line
+	}
+	sum, rest, ok := strings.Cut(rest, " ")
+	if !ok || len(sum) != 64 || strings.Trim(sum, "0123456789abcdef") != "" {
+		return line
+	}
+	return rest
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	entries := []Log{
+		{testStart, Debug, "db", []any{"query", 42}},
+		{testStart.Add(1e9), Info, "http", []any{"GET /index [ok]"}},
+		{testStart.Add(2e9), Error, "worker", []any{"failed:", "timeout"}},
+		{testStart.Add(3e9), Fatal, "main", nil},
+	}
+	want := []Record{
+		{testStart, Debug, "db", "query 42"},
+		{testStart.Add(1e9), Info, "http", "GET /index [ok]"},
+		{testStart.Add(2e9), Error, "worker", "failed: timeout"},
+		{testStart.Add(3e9), Fatal, "main", ""},
+	}
+	tests := []struct {
+		name   string
+		logger func(*bytes.Buffer) *Logger
+	}{
+		{"text", func(buf *bytes.Buffer) *Logger { return &Logger{output: buf} }},
+		{"color", func(buf *bytes.Buffer) *Logger { return &Logger{output: buf, color: true} }},
+		{"json", func(buf *bytes.Buffer) *Logger { return &Logger{output: buf, json: true} }},
+		{"audit", func(buf *bytes.Buffer) *Logger { return &Logger{output: NewAuditWriter(buf, nil)} }},
+		{"audit json", func(buf *bytes.Buffer) *Logger { return &Logger{output: NewAuditWriter(buf, nil), json: true} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := tt.logger(&buf)
+			for _, entry := range entries {
+				l.write(entry)
+			}
+			records, err := Parse(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(records) != len(want) {
+				t.Fatalf("expected %d records, got %d from %q", len(want), len(records), buf.String())
+			}
+			for i, r := range records {
+				if !r.Time.Equal(want[i].Time) || r.Level != want[i].Level || r.Label != want[i].Label || r.Msg != want[i].Msg {
+					t.Errorf("record %d: expected %+v, got %+v", i, want[i], r)
+				}
+			}
+			line := strings.SplitN(buf.String(), "\n", 2)[0]
+			r, err := ParseLine(line)
+			if err != nil || r.Msg != want[0].Msg {
+				t.Errorf("ParseLine(%q) = %+v, %v", line, r, err)
+			}
+		})
+	}
+}
+
+func TestParseContinuation(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{output: &buf}
+	l.write(Log{testStart, Error, "panic", []any{"boom\ngoroutine 1 [running]:"}})
+	l.write(Log{testStart, Info, "next", []any{"ok"}})
+	records, err := Parse(&buf)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected 2 records, got %v, %v", records, err)
+	}
+	if records[0].Msg != "boom\ngoroutine 1 [running]:" || records[1].Label != "next" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if records[1].String() != "2024/01/02 03:04:05 [INF] [next] ok" {
+		t.Fatalf("unexpected String %q", records[1].String())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, line := range []string{"", "not a log line", "2024/01/02 03:04:05 [XXX] [a] b", "2024/01/02 03:04:05 [INF] a", `{"level":"loud"}`} {
+		if _, err := ParseLine(line); err == nil {
+			t.Errorf("ParseLine(%q): expected error", line)
+		}
+	}
+	if _, err := Parse(strings.NewReader("garbage\n")); err == nil {
+		t.Fatal("expected error for leading garbage")
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	for _, level := range []Level{Debug, Info, Error, Fatal} {
+		b, err := level.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Level
+		if err = got.UnmarshalText(bytes.ToUpper(b)); err != nil || got != level {
+			t.Fatalf("expected %v, got %v, %v", level, got, err)
+		}
+	}
+	if _, err := none.MarshalText(); err == nil {
+		t.Fatal("expected error for invalid level")
+	}
+}
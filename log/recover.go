@@ -0,0 +1,99 @@
+package log
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// GoOpts defines how a goroutine started with GoWithOpts handles panics.
+type GoOpts struct {
+	// Fatal logs panics at Fatal, which exits the program, instead of Error.
+	Fatal bool
+	// Restart runs the function again after it panics.
+	Restart bool
+	// MaxRestarts limits the number of restarts. Zero means no limit.
+	MaxRestarts int
+	// Backoff is the delay before the first restart. It doubles after each restart
+	// up to MaxBackoff. They default to 1s and 1m.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Go runs fn in a new goroutine, logging any panic with its stack trace at Error.
+func (l *Logger) Go(label string, fn func()) {
+	l.GoWithOpts(label, fn, nil)
+}
+
+// GoWithOpts runs fn in a new goroutine, logging and optionally restarting it when it panics.
+func (l *Logger) GoWithOpts(label string, fn func(), opts *GoOpts) {
+	o := GoOpts{Backoff: time.Second, MaxBackoff: time.Minute}
+	if opts != nil {
+		o.Fatal = opts.Fatal
+		o.Restart = opts.Restart
+		o.MaxRestarts = opts.MaxRestarts
+		if opts.Backoff > 0 {
+			o.Backoff = opts.Backoff
+		}
+		if opts.MaxBackoff > 0 {
+			o.MaxBackoff = opts.MaxBackoff
+		}
+	}
+	go l.supervise(label, fn, &o)
+}
+
+// Recover logs a panic with its stack trace at Error and stops it from unwinding further.
+// It must be deferred directly: defer logger.Recover(label).
+func (l *Logger) Recover(label string) {
+	if r := recover(); r != nil {
+		l.logPanic(Error, label, r)
+	}
+}
+
+// RecoverFatal logs a panic with its stack trace at Fatal, which exits the program.
+// It must be deferred directly: defer logger.RecoverFatal(label).
+func (l *Logger) RecoverFatal(label string) {
+	if r := recover(); r != nil {
+		l.logPanic(Fatal, label, r)
+	}
+}
+
+// supervise runs fn until it returns without panicking or may not be restarted.
+func (l *Logger) supervise(label string, fn func(), opts *GoOpts) {
+	level := Error
+	if opts.Fatal {
+		level = Fatal
+	}
+	backoff := opts.Backoff
+	for restarts := 0; ; restarts++ {
+		if !l.call(label, fn, level) || !opts.Restart {
+			return
+		}
+		if opts.MaxRestarts > 0 && restarts >= opts.MaxRestarts {
+			l.Error(label, "giving up after", restarts, "restarts")
+			return
+		}
+		l.Info(label, "restarting in", backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, opts.MaxBackoff)
+	}
+}
+
+// call runs fn and reports whether it panicked.
+func (l *Logger) call(label string, fn func(), level Level) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			l.logPanic(level, label, r)
+		}
+	}()
+	fn()
+	return false
+}
+
+// logPanic logs a recovered panic value with the current stack trace.
+func (l *Logger) logPanic(level Level, label string, r any) {
+	if level == Fatal {
+		l.Fatal(label, "panic:", r, "\n"+string(debug.Stack()))
+	}
+	l.log(level, label, "panic:", r, "\n"+string(debug.Stack()))
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecover(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(&Opts{Output: &buf})
+	func() {
+		defer logger.Recover("worker")
+		panic("boom")
+	}()
+	logger.Shutdown()
+	out := buf.String()
+	if !strings.Contains(out, "[ERR] [worker] panic: boom") {
+		t.Fatalf("expected panic at Error, got %q", out)
+	}
+	if !strings.Contains(out, "goroutine ") || !strings.Contains(out, "TestRecover") {
+		t.Fatalf("expected stack trace, got %q", out)
+	}
+}
+
+func TestGoRestart(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(&Opts{Output: &buf})
+	done := make(chan struct{})
+	runs := 0
+	logger.GoWithOpts("worker", func() {
+		runs++
+		if runs < 5 {
+			panic("boom")
+		}
+		close(done)
+	}, &GoOpts{Restart: true, Backoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for restarts")
+	}
+	logger.Shutdown()
+	out := buf.String()
+	if n := strings.Count(out, "panic: boom"); n != 4 {
+		t.Fatalf("expected 4 panics, got %d in %q", n, out)
+	}
+	var backoffs []string
+	for _, line := range strings.Split(out, "\n") {
+		if _, after, ok := strings.Cut(line, "restarting in "); ok {
+			backoffs = append(backoffs, after)
+		}
+	}
+	if got := strings.Join(backoffs, " "); got != "1ms 2ms 4ms 4ms" {
+		t.Fatalf("expected doubling capped backoff, got %q", got)
+	}
+}
+
+func TestGoMaxRestarts(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(&Opts{Output: &buf})
+	runs := make(chan struct{}, 10)
+	logger.GoWithOpts("worker", func() {
+		runs <- struct{}{}
+		panic("boom")
+	}, &GoOpts{Restart: true, MaxRestarts: 2, Backoff: time.Millisecond})
+	deadline := time.After(5 * time.Second)
+	for !strings.Contains(buf.String(), "giving up") {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting to give up, got %q", buf.String())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	logger.Shutdown()
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	if out := buf.String(); !strings.Contains(out, "[ERR] [worker] giving up after 2 restarts") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
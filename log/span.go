@@ -0,0 +1,77 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// Span measures the duration and outcome of an operation and logs it when ended.
+type Span struct {
+	logger *Logger
+	label  string
+	op     string
+	start  time.Time
+	slow   time.Duration
+	fields []any
+	err    error
+}
+
+// Span starts measuring an operation. Use it as defer logger.Span(label, op).End().
+func (l *Logger) Span(label, op string) *Span {
+	return &Span{
+		logger: l,
+		label:  label,
+		op:     op,
+		start:  time.Now(),
+		slow:   l.slow,
+	}
+}
+
+// Time runs fn as an operation, logs its duration and outcome and returns its error.
+func (l *Logger) Time(label, op string, fn func() error) error {
+	s := l.Span(label, op)
+	err := fn()
+	s.Fail(err).End()
+	return err
+}
+
+// With adds key-value pairs that are logged with the span.
+func (s *Span) With(keyvals ...any) *Span {
+	s.fields = append(s.fields, keyvals...)
+	return s
+}
+
+// Slow overrides the logger's SlowThreshold for this span.
+func (s *Span) Slow(threshold time.Duration) *Span {
+	s.slow = threshold
+	return s
+}
+
+// Fail records the outcome of the operation. Spans that end with a non-nil error are logged at Error.
+func (s *Span) Fail(err error) *Span {
+	s.err = err
+	return s
+}
+
+// End logs the span and returns its duration.
+func (s *Span) End() time.Duration {
+	elapsed := time.Since(s.start)
+	level := Info
+	if s.err != nil || (s.slow > 0 && elapsed >= s.slow) {
+		level = Error
+	}
+	msg := make([]any, 0, 4+len(s.fields)/2)
+	msg = append(msg, s.op, "took", elapsed)
+	for i := 0; i < len(s.fields); i += 2 {
+		if i+1 < len(s.fields) {
+			msg = append(msg, fmt.Sprintf("%v=%v", s.fields[i], s.fields[i+1]))
+		} else {
+			msg = append(msg, s.fields[i])
+		}
+	}
+	if s.err != nil {
+		msg = append(msg, "error="+s.err.Error())
+	}
+	s.logger.log(level, s.label, msg...)
+	return elapsed
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpan(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(&Opts{Output: &buf, SlowThreshold: time.Nanosecond})
+	slow := logger.Span("span", "slow")
+	time.Sleep(time.Millisecond)
+	if elapsed := slow.End(); elapsed < time.Millisecond {
+		t.Fatalf("expected elapsed of at least 1ms, got %v", elapsed)
+	}
+	logger.Span("span", "override").Slow(time.Hour).End()
+	logger.Span("span", "disabled").Slow(0).End()
+	err := logger.Time("span", "failing", func() error { return errors.New("boom") })
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("expected Time to return the error, got %v", err)
+	}
+	logger.Span("span", "fields").Slow(time.Hour).With("user", "bob", "n", 3).With("odd").End()
+	logger.Shutdown()
+
+	records, err := Parse(strings.NewReader(buf.String()))
+	if err != nil || len(records) != 5 {
+		t.Fatalf("expected 5 records, got %v, %v", records, err)
+	}
+	want := []struct {
+		level  Level
+		prefix string
+		suffix string
+	}{
+		{Error, "slow took ", ""},
+		{Info, "override took ", ""},
+		{Info, "disabled took ", ""},
+		{Error, "failing took ", " error=boom"},
+		{Info, "fields took ", " user=bob n=3 odd"},
+	}
+	for i, w := range want {
+		r := records[i]
+		if r.Level != w.level || r.Label != "span" || !strings.HasPrefix(r.Msg, w.prefix) || !strings.HasSuffix(r.Msg, w.suffix) {
+			t.Errorf("record %d: expected %v %q...%q, got %v %q", i, w.level, w.prefix, w.suffix, r.Level, r.Msg)
+		}
+	}
+}